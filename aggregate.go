@@ -0,0 +1,203 @@
+package csv
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// distinctSketchSize is the number of hashes kept to estimate AggregateDistinct, up to this
+// number of distinct values the count is exact.
+const distinctSketchSize = 1024
+
+// Aggregation selects the aggregations of a field, they are combined with |.
+type Aggregation int
+
+const (
+	AggregateCount    Aggregation = 1 << iota // number of rows with a value
+	AggregateSum                              // sum of the values of a numeric field
+	AggregateMin                              // minimum of the values of a numeric field
+	AggregateMax                              // maximum of the values of a numeric field
+	AggregateDistinct                         // number of distinct values, estimated above 1024 values
+)
+
+// AggregateSpec maps struct field names to their aggregations.
+type AggregateSpec map[string]Aggregation
+
+// ColumnAggregate is the result of the aggregations of a field. Rows is always set, the other
+// values only for the requested aggregations.
+type ColumnAggregate struct {
+	Rows     int // number of rows that contributed a value
+	Sum      float64
+	Min      float64
+	Max      float64
+	Distinct int
+	sketch   *distinctSketch
+}
+
+// AggregateResult maps the struct field names of an AggregateSpec to their results.
+type AggregateResult map[string]ColumnAggregate
+
+// Aggregate parses a csv file and aggregates field values without keeping the records; only the
+// fields of spec are decoded, like after Select, into a single reused struct. Scaled integers are
+// aggregated as decimal numbers. Errors are returned like by Unmarshal, rows that cannot be decoded
+// do not contribute. DedupBy is not applied.
+func (m *Marshaler) Aggregate(spec AggregateSpec) (AggregateResult, error) {
+	records := &aggregateRecords{discardRecords: discardRecords{typ: reflect.TypeOf(m.endPointStruct)}, result: AggregateResult{}}
+	fieldNames := make([]string, 0, len(spec))
+	for fieldName, aggregations := range spec {
+		i := m.allFieldInfos.index(fieldName)
+		if i < 0 {
+			return nil, fmt.Errorf("no csv field: %s", fieldName)
+		}
+		fieldInfo := m.allFieldInfos[i]
+		if aggregations&(AggregateSum|AggregateMin|AggregateMax) != 0 && !isNumeric(fieldInfo.kind) {
+			return nil, fmt.Errorf("cannot aggregate field %s of kind %s", fieldName, fieldInfo.kind)
+		}
+		if fieldInfo.isGlob() {
+			return nil, fmt.Errorf("cannot aggregate glob field: %s", fieldName)
+		}
+		aggregate := ColumnAggregate{Min: math.Inf(1), Max: math.Inf(-1)}
+		if aggregations&AggregateDistinct != 0 {
+			aggregate.sketch = &distinctSketch{}
+		}
+		records.fields = append(records.fields, aggregateField{fieldInfo: fieldInfo, aggregations: aggregations})
+		records.result[fieldName] = aggregate
+		fieldNames = append(fieldNames, fieldName)
+	}
+	saved, dedupField := m.fieldInfos, m.dedupField
+	defer func() { m.fieldInfos, m.dedupField = saved, dedupField }()
+	if err := m.Select(fieldNames...); err != nil {
+		return nil, err
+	}
+	m.dedupField = ""
+
+	err := m.unmarshalRecords(m.Reader, -1, records)
+	if err == nil && len(m.errors) > 0 {
+		err = m.errors
+	}
+	if _, ok := err.(ParseErrors); err != nil && !ok {
+		return nil, err
+	}
+	for _, field := range records.fields {
+		aggregate := records.result[field.fieldInfo.fieldName]
+		if aggregate.sketch != nil {
+			aggregate.Distinct = aggregate.sketch.estimate()
+			aggregate.sketch = nil
+		}
+		if aggregate.Rows == 0 || field.aggregations&AggregateMin == 0 {
+			aggregate.Min = 0
+		}
+		if aggregate.Rows == 0 || field.aggregations&AggregateMax == 0 {
+			aggregate.Max = 0
+		}
+		records.result[field.fieldInfo.fieldName] = aggregate
+	}
+	return records.result, err
+}
+
+// aggregateField is a field of an AggregateSpec.
+type aggregateField struct {
+	fieldInfo    fieldInfo
+	aggregations Aggregation
+}
+
+// aggregateRecords decodes every record into the same struct and aggregates its fields.
+type aggregateRecords struct {
+	discardRecords
+	fields []aggregateField
+	result AggregateResult
+	writer Writer // formats the values of AggregateDistinct
+}
+
+func (r *aggregateRecords) add(i int) error {
+	r.discardRecords.add(i)
+	v := r.ptr.Elem()
+	for _, field := range r.fields {
+		value := v.FieldByName(field.fieldInfo.fieldName)
+		aggregate := r.result[field.fieldInfo.fieldName]
+		aggregate.Rows++
+		if isNumeric(field.fieldInfo.kind) {
+			f := toFloat(field.fieldInfo, value)
+			aggregate.Sum += f
+			aggregate.Min = math.Min(aggregate.Min, f)
+			aggregate.Max = math.Max(aggregate.Max, f)
+		}
+		if aggregate.sketch != nil {
+			if cell, err := r.writer.format(field.fieldInfo, value); err == nil {
+				aggregate.sketch.add(cell)
+			}
+		}
+		r.result[field.fieldInfo.fieldName] = aggregate
+	}
+	return nil
+}
+
+// isNumeric checks if fields of kind can be summed up.
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// toFloat returns the numeric value v of the field as float64.
+func toFloat(fieldInfo fieldInfo, v reflect.Value) float64 {
+	switch fieldInfo.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()) / math.Pow10(fieldInfo.scale)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+// distinctSketch estimates the number of distinct values with the k minimum values of their
+// 64 bit hashes, k is distinctSketchSize. Memory is bounded by k, up to k values the count is exact.
+type distinctSketch struct {
+	hashes    []uint64 // smallest hashes in ascending order
+	saturated bool     // if true, hashes were dropped and the count is estimated
+}
+
+func (s *distinctSketch) add(value string) {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	hash := mix(h.Sum64())
+	i := sort.Search(len(s.hashes), func(i int) bool { return s.hashes[i] >= hash })
+	if i < len(s.hashes) && s.hashes[i] == hash {
+		return
+	}
+	if len(s.hashes) == distinctSketchSize {
+		s.saturated = true
+		if i == len(s.hashes) {
+			return
+		}
+		s.hashes = s.hashes[:len(s.hashes)-1]
+	}
+	s.hashes = append(s.hashes, 0)
+	copy(s.hashes[i+1:], s.hashes[i:])
+	s.hashes[i] = hash
+}
+
+func (s *distinctSketch) estimate() int {
+	if !s.saturated {
+		return len(s.hashes)
+	}
+	kth := float64(s.hashes[len(s.hashes)-1]) / math.MaxUint64
+	return int(float64(distinctSketchSize-1) / kth)
+}
+
+// mix is the finalizer of MurmurHash3, it spreads the fnv hashes of similar values uniformly.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
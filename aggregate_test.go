@@ -0,0 +1,62 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,false,2.5\nc,x,true,1.5\na,4,true,-0.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Aggregate(AggregateSpec{
+		"Field0": AggregateCount | AggregateDistinct,
+		"Field1": AggregateSum | AggregateMin | AggregateMax,
+		"Field3": AggregateSum | AggregateDistinct,
+	})
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("wrong errors - want: 1 ParseError, got: %v", err)
+	}
+	want := AggregateResult{
+		"Field0": {Rows: 3, Distinct: 2},
+		"Field1": {Rows: 3, Sum: 7, Min: 1, Max: 4},
+		"Field3": {Rows: 3, Sum: 3.5, Distinct: 3},
+	}
+	for name, aggregate := range want {
+		if got := result[name]; got != aggregate {
+			t.Errorf("%s: wrong aggregate - want: %+v, got: %+v", name, aggregate, got)
+		}
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Aggregate(AggregateSpec{"Field0": AggregateSum}); err == nil {
+		t.Error("no error for the sum of a string field, but it should")
+	}
+	if _, err := m.Aggregate(AggregateSpec{"Field4": AggregateCount}); err == nil {
+		t.Error("no error for an unknown field, but it should")
+	}
+}
+
+func TestDistinctSketch(t *testing.T) {
+	for _, n := range []int{10, distinctSketchSize, 100000} {
+		s := &distinctSketch{}
+		for i := 0; i < 3*n; i++ {
+			s.add(fmt.Sprint(i % n))
+		}
+		if len(s.hashes) > distinctSketchSize {
+			t.Errorf("%d: sketch not bounded: %d hashes", n, len(s.hashes))
+		}
+		if got := s.estimate(); math.Abs(float64(got-n)) > 0.1*float64(n) {
+			t.Errorf("%d: wrong estimate: %d", n, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// validateBoolNum checks that the boolnum option, e.g. `csv:"FLAG,boolnum"`, is only used for
+// bool fields.
+func validateBoolNum(typ reflect.Type, options tagOptions) error {
+	if _, ok := options["boolnum"]; !ok {
+		return nil
+	}
+	if typ.Kind() != reflect.Bool {
+		return fmt.Errorf("option boolnum requires a bool field, got: %s", typ)
+	}
+	return nil
+}
+
+// parseBoolNum parses an integer cell, zero is false and every other number true.
+func parseBoolNum(cell string) (bool, error) {
+	n, err := strconv.ParseInt(cell, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
+
+// formatBoolNum formats b as 1 or 0.
+func formatBoolNum(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,73 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type BoolNumStruct struct {
+	Name string `csv:"NAME"`
+	Flag bool   `csv:"FLAG,boolnum"`
+}
+
+func TestBoolNum(t *testing.T) {
+	tt := map[string]struct {
+		cell    string
+		want    bool
+		wantErr error
+	}{
+		"zero":     {cell: "0", want: false},
+		"one":      {cell: "1", want: true},
+		"two":      {cell: "2", want: true},
+		"negative": {cell: "-1", want: true},
+		"minus 0":  {cell: "-0", want: false},
+		"text":     {cell: "true", wantErr: strconv.ErrSyntax},
+		"empty":    {cell: "", wantErr: strconv.ErrSyntax},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(BoolNumStruct{}, strings.NewReader("NAME,FLAG\na,"+tc.cell+"\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.Unmarshal()
+			if tc.wantErr != nil {
+				var errs ParseErrors
+				if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs[0].Err, tc.wantErr) {
+					t.Errorf("wrong error - want: %v, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result[0].(BoolNumStruct).Flag; got != tc.want {
+				t.Errorf("wrong flag - want: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBoolNumWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(BoolNumStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal([]interface{}{BoolNumStruct{Name: "a", Flag: true}, BoolNumStruct{Name: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME,FLAG\na,1\nb,0\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+
+	type NoBool struct {
+		Flag int `csv:"FLAG,boolnum"`
+	}
+	if _, err := NewSchema(NoBool{}); err == nil {
+		t.Error("no error for boolnum on an int field, but it should")
+	}
+}
@@ -0,0 +1,67 @@
+package csv
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+)
+
+// builtinConverters decode standard library types that do not implement encoding.TextUnmarshaler.
+// net.IP implements it and needs no entry.
+var builtinConverters = map[reflect.Type]func(string) (interface{}, error){
+	reflect.TypeOf(net.IPNet{}): func(s string) (interface{}, error) {
+		ipNet, err := parseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		return *ipNet, nil
+	},
+	reflect.TypeOf(&net.IPNet{}): func(s string) (interface{}, error) {
+		return parseCIDR(s)
+	},
+	reflect.TypeOf(url.URL{}): func(s string) (interface{}, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	},
+	reflect.TypeOf(&url.URL{}): func(s string) (interface{}, error) {
+		return url.Parse(s)
+	},
+}
+
+// builtinFormatters encode the types of builtinConverters.
+var builtinFormatters = map[reflect.Type]func(interface{}) (string, error){
+	reflect.TypeOf(net.IPNet{}): func(v interface{}) (string, error) {
+		ipNet := v.(net.IPNet)
+		return formatCIDR(&ipNet), nil
+	},
+	reflect.TypeOf(&net.IPNet{}): func(v interface{}) (string, error) {
+		return formatCIDR(v.(*net.IPNet)), nil
+	},
+	reflect.TypeOf(url.URL{}): func(v interface{}) (string, error) {
+		u := v.(url.URL)
+		return u.String(), nil
+	},
+	reflect.TypeOf(&url.URL{}): func(v interface{}) (string, error) {
+		if u := v.(*url.URL); u != nil {
+			return u.String(), nil
+		}
+		return "", nil
+	},
+}
+
+// parseCIDR parses a network in CIDR notation, the address is masked to the network address.
+func parseCIDR(s string) (*net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(s)
+	return ipNet, err
+}
+
+// formatCIDR formats a network in CIDR notation, nil and zero networks result in an empty cell.
+func formatCIDR(ipNet *net.IPNet) string {
+	if ipNet == nil || ipNet.IP == nil {
+		return ""
+	}
+	return ipNet.String()
+}
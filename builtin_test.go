@@ -0,0 +1,70 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type NetworkStruct struct {
+	IP       net.IP     `csv:"IP"`
+	Network  net.IPNet  `csv:"NETWORK"`
+	Gateway  *net.IPNet `csv:"GATEWAY"`
+	Homepage url.URL    `csv:"HOMEPAGE"`
+	API      *url.URL   `csv:"API"`
+}
+
+func TestUnmarshalBuiltinTypes(t *testing.T) {
+	data := `IP;NETWORK;GATEWAY;HOMEPAGE;API
+10.0.0.5;10.0.0.0/24;192.168.1.1/16;https://example.com/a?b=c;http://localhost:8080
+::1;fe80::/10;10.0.0.0/8;/relative;http://localhost
+10.0.0.256;10.0.0.0/24;10.0.0.0/8;https://example.com;http://localhost
+10.0.0.1;10.0.0.0/33;10.0.0.0/8;https://example.com;http://localhost
+10.0.0.1;10.0.0.0/24;10.0.0.0/8;https://example.com;http://[::1`
+	m, err := NewMarshaler(NetworkStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	if len(result) != 2 {
+		t.Fatalf("wrong number of results - want: %d, got: %d", 2, len(result))
+	}
+	got := result[0].(NetworkStruct)
+	if !got.IP.Equal(net.ParseIP("10.0.0.5")) || got.Network.String() != "10.0.0.0/24" ||
+		got.Gateway.String() != "192.168.0.0/16" || got.Homepage.Query().Get("b") != "c" || got.API.Port() != "8080" {
+		t.Errorf("wrong result: %v", got)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 3 {
+		t.Fatalf("wrong errors - want: 3 ParseErrors, got: %v", err)
+	}
+	wantHeaders := []string{"IP", "NETWORK", "API"}
+	for i, e := range pe {
+		var fe *FieldError
+		if !errors.As(e.Err, &fe) || fe.Header != wantHeaders[i] {
+			t.Errorf("wrong error %d - want: error for %s, got: %v", i, wantHeaders[i], e)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(NetworkStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Writer.Comma = ';'
+	if err := w.Marshal(append(result, NetworkStruct{})); err != nil {
+		t.Fatal(err)
+	}
+	want := `IP;NETWORK;GATEWAY;HOMEPAGE;API
+10.0.0.5;10.0.0.0/24;192.168.0.0/16;https://example.com/a?b=c;http://localhost:8080
+::1;fe80::/10;10.0.0.0/8;/relative;http://localhost
+;;;;
+`
+	if buf.String() != want {
+		t.Errorf("wrong csv - want: %q, got: %q", want, buf.String())
+	}
+}
@@ -0,0 +1,144 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+)
+
+// ChunkError reports the chunk a write error occured in.
+type ChunkError struct {
+	Chunk int
+	Err   error
+}
+
+// Error returns the ChunkError as string
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk:%d,err:%s", e.Chunk, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// ChunkedWriter marshals endpoint structs to multiple csv files, each starting with the header.
+// A new chunk is started as soon as the current one reached MaxRecords records or MaxBytes bytes.
+type ChunkedWriter struct {
+	MaxRecords int             // maximum number of records per chunk, 0 means unlimited
+	MaxBytes   int64           // maximum size of a chunk in bytes, 0 means unlimited; a chunk may exceed it by its last record
+	TSV        bool            // if true, the chunks are tab-separated like the output of NewTSVWriter
+	Setup      func(w *Writer) // if not nil, called for every new chunk Writer, e.g. to set the Comma
+	create     func(index int) (io.WriteCloser, error)
+	schema     *Schema
+	index      int
+	sink       io.WriteCloser
+	counter    *countingWriter
+	writer     *Writer
+	records    int
+}
+
+// NewChunkedWriter returns a new ChunkedWriter. The create function is called with
+// the zero based chunk index whenever a new chunk is started.
+func NewChunkedWriter(endPointStruct interface{}, create func(index int) (io.WriteCloser, error)) (*ChunkedWriter, error) {
+	schema, err := NewSchema(endPointStruct)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkedWriter{
+		create: create,
+		schema: schema,
+		index:  -1,
+	}, nil
+}
+
+// Write writes the endpoint struct s to the current chunk and starts a new chunk if necessary.
+func (c *ChunkedWriter) Write(s interface{}) error {
+	if c.writer == nil || c.isFull() {
+		if err := c.roll(); err != nil {
+			return err
+		}
+	}
+	if err := c.writer.write(s); err != nil {
+		return &ChunkError{Chunk: c.index, Err: err}
+	}
+	c.records++
+	if c.MaxBytes > 0 {
+		// flush to get an exact byte count
+		if err := c.writer.Flush(); err != nil {
+			return &ChunkError{Chunk: c.index, Err: err}
+		}
+	}
+	return nil
+}
+
+// Marshal writes all endpoint structs in structs and closes the last chunk.
+func (c *ChunkedWriter) Marshal(structs []interface{}) error {
+	for _, s := range structs {
+		if err := c.Write(s); err != nil {
+			return err
+		}
+	}
+	return c.Close()
+}
+
+// Close flushes and closes the current chunk.
+func (c *ChunkedWriter) Close() error {
+	if c.writer == nil {
+		return nil
+	}
+	err := c.writer.Flush()
+	if cerr := c.sink.Close(); err == nil {
+		err = cerr
+	}
+	c.writer, c.sink, c.counter = nil, nil, nil
+	if err != nil {
+		return &ChunkError{Chunk: c.index, Err: err}
+	}
+	return nil
+}
+
+// isFull checks if the current chunk reached one of its limits.
+func (c *ChunkedWriter) isFull() bool {
+	if c.MaxRecords > 0 && c.records >= c.MaxRecords {
+		return true
+	}
+	return c.MaxBytes > 0 && c.counter.n >= c.MaxBytes
+}
+
+// roll closes the current chunk and starts the next one with a header.
+func (c *ChunkedWriter) roll() error {
+	if err := c.Close(); err != nil {
+		return err
+	}
+	c.index++
+	c.records = 0
+	sink, err := c.create(c.index)
+	if err != nil {
+		return &ChunkError{Chunk: c.index, Err: err}
+	}
+	c.sink = sink
+	c.counter = &countingWriter{w: sink}
+	c.writer = NewWriterWithSchema(c.schema, c.counter)
+	if c.TSV {
+		c.writer.useTSV()
+	}
+	if c.Setup != nil {
+		c.Setup(c.writer)
+	}
+	if err := c.writer.writeHeader(); err != nil {
+		return &ChunkError{Chunk: c.index, Err: err}
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,182 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type chunkSink struct {
+	bytes.Buffer
+	closed bool
+	err    error
+}
+
+func (s *chunkSink) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.Buffer.Write(p)
+}
+
+func (s *chunkSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func newChunkSinks(sinks *[]*chunkSink) func(int) (io.WriteCloser, error) {
+	return func(index int) (io.WriteCloser, error) {
+		s := &chunkSink{}
+		*sinks = append(*sinks, s)
+		return s, nil
+	}
+}
+
+func testStructs(n int) []interface{} {
+	structs := []interface{}{}
+	for i := 0; i < n; i++ {
+		structs = append(structs, TestStruct{Field0: "string", Field1: i, Field2: true, Field3: 1.5})
+	}
+	return structs
+}
+
+func TestChunkedWriterMaxRecords(t *testing.T) {
+	sinks := []*chunkSink{}
+	c, err := NewChunkedWriter(TestStruct{}, newChunkSinks(&sinks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.MaxRecords = 2
+	c.Setup = func(w *Writer) { w.Writer.Comma = ';' }
+	if err := c.Marshal(testStructs(5)); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	want := []string{
+		"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring;0;true;1.5\nstring;1;true;1.5\n",
+		"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring;2;true;1.5\nstring;3;true;1.5\n",
+		"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring;4;true;1.5\n",
+	}
+	if len(sinks) != len(want) {
+		t.Fatalf("wrong number of chunks - want: %d, got: %d", len(want), len(sinks))
+	}
+	for i, s := range sinks {
+		if s.String() != want[i] {
+			t.Errorf("wrong content of chunk %d - want: %q, got: %q", i, want[i], s.String())
+		}
+		if !s.closed {
+			t.Errorf("chunk %d not closed", i)
+		}
+	}
+}
+
+func TestChunkedWriterMaxBytes(t *testing.T) {
+	sinks := []*chunkSink{}
+	c, err := NewChunkedWriter(TestStruct{}, newChunkSinks(&sinks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header has 32 bytes, every record 18 bytes
+	c.MaxBytes = 60
+	if err := c.Marshal(testStructs(5)); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	if len(sinks) != 3 {
+		t.Fatalf("wrong number of chunks - want: %d, got: %d", 3, len(sinks))
+	}
+	for i, s := range sinks {
+		if !bytes.HasPrefix(s.Bytes(), []byte("FIELD_0,FIELD_1,FIELD_2,FIELD_3\n")) {
+			t.Errorf("chunk %d does not start with header: %q", i, s.String())
+		}
+		if !s.closed {
+			t.Errorf("chunk %d not closed", i)
+		}
+	}
+}
+
+func TestChunkedWriterErrors(t *testing.T) {
+	sinkErr := errors.New("disk full")
+	sinks := []*chunkSink{}
+	c, err := NewChunkedWriter(TestStruct{}, func(index int) (io.WriteCloser, error) {
+		s := &chunkSink{}
+		if index == 1 {
+			s.err = sinkErr
+		}
+		sinks = append(sinks, s)
+		return s, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.MaxRecords = 2
+	err = c.Marshal(testStructs(5))
+	ce, ok := err.(*ChunkError)
+	if !ok {
+		t.Fatalf("wrong error - want: *ChunkError, got: %v", err)
+	}
+	if ce.Chunk != 1 || !errors.Is(err, sinkErr) {
+		t.Errorf("wrong chunk error - want chunk 1 with %s, got: %s", sinkErr, ce)
+	}
+
+	createErr := errors.New("cannot create file")
+	c, err = NewChunkedWriter(TestStruct{}, func(index int) (io.WriteCloser, error) {
+		return nil, createErr
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(TestStruct{}); !errors.Is(err, createErr) {
+		t.Errorf("wrong error - want: %s, got: %v", createErr, err)
+	}
+}
+
+func TestChunkedWriterEmptyQuoted(t *testing.T) {
+	sinks := []*chunkSink{}
+	c, err := NewChunkedWriter(EmptyQuotedStruct{}, newChunkSinks(&sinks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header has 27 bytes, every record 9 bytes
+	c.MaxBytes = 30
+	structs := []interface{}{EmptyQuotedStruct{Name: "a"}, EmptyQuotedStruct{Name: "b"}}
+	if err := c.Marshal(structs); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	want := []string{
+		"NAME,NOTE,ALIAS,CODE,COUNT\na,\"\",,,\n",
+		"NAME,NOTE,ALIAS,CODE,COUNT\nb,\"\",,,\n",
+	}
+	if len(sinks) != len(want) {
+		t.Fatalf("wrong number of chunks - want: %d, got: %d", len(want), len(sinks))
+	}
+	for i, s := range sinks {
+		if s.String() != want[i] {
+			t.Errorf("wrong content of chunk %d - want: %q, got: %q", i, want[i], s.String())
+		}
+	}
+}
+
+func TestChunkedWriterTSV(t *testing.T) {
+	sinks := []*chunkSink{}
+	c, err := NewChunkedWriter(TestStruct{}, newChunkSinks(&sinks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.TSV = true
+	c.MaxRecords = 2
+	if err := c.Marshal(testStructs(3)); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	want := []string{
+		"FIELD_0\tFIELD_1\tFIELD_2\tFIELD_3\nstring\t0\ttrue\t1.5\nstring\t1\ttrue\t1.5\n",
+		"FIELD_0\tFIELD_1\tFIELD_2\tFIELD_3\nstring\t2\ttrue\t1.5\n",
+	}
+	if len(sinks) != len(want) {
+		t.Fatalf("wrong number of chunks - want: %d, got: %d", len(want), len(sinks))
+	}
+	for i, s := range sinks {
+		if s.String() != want[i] {
+			t.Errorf("wrong content of chunk %d - want: %q, got: %q", i, want[i], s.String())
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package csv
+
+import (
+	"errors"
+	"strings"
+)
+
+// parseComposite returns the header names of a field with the composite option and a header name
+// like DATE+TIME and nil for all other fields, a + in the header name of other fields is part of
+// the name. The cells of a composite field are joined with the join option, a space by default,
+// e.g. `csv:"DATE+TIME,composite,format=02.01.2006 15:04"`.
+func parseComposite(headerName string, options tagOptions) ([]string, error) {
+	if _, ok := options["composite"]; !ok {
+		if _, ok := options["join"]; ok {
+			return nil, errors.New("option join requires the composite option")
+		}
+		return nil, nil
+	}
+	parts := strings.Split(headerName, "+")
+	composite := len(parts) > 1
+	for _, part := range parts {
+		composite = composite && len(part) > 0
+	}
+	if !composite {
+		return nil, errors.New("option composite requires a header name like A+B")
+	}
+	if _, ok := options["glob"]; ok {
+		return nil, errors.New("glob fields cannot be composite")
+	}
+	return parts, nil
+}
+
+// isComposite checks if the field is decoded from the joined cells of several columns.
+func (fieldInfo fieldInfo) isComposite() bool {
+	return len(fieldInfo.parts) > 0
+}
+
+// resolveComposite sets the positions of all parts of a composite field and the position to the
+// one of the first part. It returns the first part without column.
+func (fieldInfo *fieldInfo) resolveComposite(columns map[string]int) string {
+	fieldInfo.position, fieldInfo.positions = -1, nil
+	for _, part := range fieldInfo.parts {
+		position, ok := columns[part]
+		if !ok {
+			return part
+		}
+		fieldInfo.positions = append(fieldInfo.positions, position)
+	}
+	fieldInfo.position = fieldInfo.positions[0]
+	return ""
+}
+
+// joinCells joins the cells of the parts of a composite field, the result is empty if all cells
+// are empty. Missing cells are handled like in cell.
+func (m *Marshaler) joinCells(fieldInfo fieldInfo, record []string) (string, error) {
+	separator, ok := fieldInfo.options["join"]
+	if !ok {
+		separator = " "
+	}
+	cells := make([]string, 0, len(fieldInfo.positions))
+	empty := true
+	for _, position := range fieldInfo.positions {
+		cell := ""
+		if position < len(record) {
+			cell = record[position]
+		} else if m.ErrorOnMissingCells {
+			return "", ErrMissingCell
+		}
+		empty = empty && len(cell) == 0
+		cells = append(cells, cell)
+	}
+	if empty {
+		return "", nil
+	}
+	return strings.Join(cells, separator), nil
+}
@@ -0,0 +1,109 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CompositeStruct struct {
+	Name string    `csv:"NAME"`
+	At   time.Time `csv:"DATE+TIME,composite,format=02.01.2006 15:04"`
+}
+
+func TestUnmarshalComposite(t *testing.T) {
+	data := "TIME,NAME,DATE\n15:04,first,24.12.2020\n09:30,second,01.01.2021\n"
+	m, err := NewMarshaler(CompositeStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CompositeStruct{
+		{Name: "first", At: time.Date(2020, 12, 24, 15, 4, 0, 0, time.UTC)},
+		{Name: "second", At: time.Date(2021, 1, 1, 9, 30, 0, 0, time.UTC)},
+	}
+	if len(result) != len(want) {
+		t.Fatalf("wrong number of results - want: %d, got: %d", len(want), len(result))
+	}
+	for i, s := range result {
+		got := s.(CompositeStruct)
+		if got.Name != want[i].Name || !got.At.Equal(want[i].At) {
+			t.Errorf("wrong result %d - want: %v, got: %v", i, want[i], got)
+		}
+	}
+
+	m, err = NewMarshaler(CompositeStruct{}, strings.NewReader("NAME,DATE\nfirst,24.12.2020\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Unmarshal()
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrHeaderNotComplete) {
+		t.Fatalf("wrong error - want: %s, got: %v", ErrHeaderNotComplete, err)
+	}
+	if !strings.Contains(err.Error(), "TIME of DATE+TIME") {
+		t.Errorf("error does not name the missing column: %s", err)
+	}
+}
+
+func TestCompositeJoin(t *testing.T) {
+	type Joined struct {
+		Code string `csv:"PREFIX+NUMBER,composite,join=-"`
+	}
+	m, err := NewMarshaler(Joined{}, strings.NewReader("PREFIX,NUMBER\nCH,42\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result[0].(Joined).Code; got != "CH-42" {
+		t.Errorf("wrong code - want: %s, got: %s", "CH-42", got)
+	}
+	header, err := Header(Joined{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(header) != 0 {
+		t.Errorf("composite field in header: %v", header)
+	}
+}
+
+func TestCompositeInvalidTags(t *testing.T) {
+	type Join struct {
+		Code string `csv:"CODE,join=-"`
+	}
+	type Glob struct {
+		Codes map[string]string `csv:"A+B*,glob,composite"`
+	}
+	for name, s := range map[string]interface{}{"join": Join{}, "glob": Glob{}} {
+		if _, err := NewSchema(s); err == nil {
+			t.Errorf("%s: no error, but it should", name)
+		}
+	}
+}
+
+func TestPlusInHeaderName(t *testing.T) {
+	type Plus struct {
+		Price string `csv:"PRICE+TAX"`
+		Lang  string `csv:"C++"`
+	}
+	m, err := NewMarshaler(Plus{}, strings.NewReader("PRICE+TAX,C++\n12.50,yes\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Plus{Price: "12.50", Lang: "yes"}
+	if got := result[0].(Plus); got != want {
+		t.Errorf("wrong result - want: %v, got: %v", want, got)
+	}
+}
@@ -0,0 +1,140 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FileErrorPolicy defines how UnmarshalConcurrent handles files that fail to parse.
+type FileErrorPolicy int
+
+const (
+	FailFast          FileErrorPolicy = iota // the first failing file cancels all other files
+	CollectFileErrors                        // all files are parsed, failing files are left out of the result
+)
+
+// FileError reports the file an error occured in. Err is either the ParseErrors of a Lazy
+// Marshaler or the error that stopped parsing the file.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the FileError as string
+func (e *FileError) Error() string {
+	return fmt.Sprintf("file:%s,err:%s", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileErrors is a slice of FileError in path order.
+type FileErrors []*FileError
+
+// Error returns the FileErrors as string, one file per line
+func (errs FileErrors) Error() string {
+	s := ""
+	for _, err := range errs {
+		s = s + strings.TrimSuffix(err.Error(), "\n") + "\n"
+	}
+	return s
+}
+
+// fileResult is the outcome of a single file.
+type fileResult struct {
+	structs []interface{}
+	err     error
+	fatal   bool
+}
+
+// UnmarshalConcurrent parses the files of paths with up to workers Marshalers configured with opts
+// in parallel and returns the endpoint structs of all files in path order. Errors are returned
+// as FileErrors: row errors of Lazy Marshalers never fail a file, with FailFast the first other
+// error cancels the remaining files and is returned as *FileError. A cancelled ctx stops parsing
+// and its error is returned.
+func UnmarshalConcurrent(ctx context.Context, paths []string, prototype interface{}, workers int, policy FileErrorPolicy, opts ...Option) ([]interface{}, error) {
+	schema, err := NewSchema(prototype)
+	if err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	results := make([]fileResult, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = unmarshalFile(ctx, schema, paths[i], opts)
+				if results[i].fatal && policy == FailFast {
+					cancel()
+				}
+			}
+		}()
+	}
+feed:
+	for i := range paths {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+
+	structs := []interface{}{}
+	fileErrors := FileErrors{}
+	for i, result := range results {
+		if result.err != nil {
+			fileError := &FileError{Path: paths[i], Err: result.err}
+			if result.fatal && policy == FailFast && !errors.Is(result.err, context.Canceled) {
+				return nil, fileError
+			}
+			fileErrors = append(fileErrors, fileError)
+		}
+		if !result.fatal {
+			structs = append(structs, result.structs...)
+		}
+	}
+	if len(fileErrors) > 0 {
+		return structs, fileErrors
+	}
+	return structs, nil
+}
+
+// unmarshalFile parses the file path with a new Marshaler, reads fail as soon as ctx is done.
+func unmarshalFile(ctx context.Context, schema *Schema, path string, opts []Option) fileResult {
+	if err := ctx.Err(); err != nil {
+		return fileResult{err: err, fatal: true}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fileResult{err: err, fatal: true}
+	}
+	defer f.Close()
+	opts = append([]Option{WithSource(path)}, opts...)
+	m := NewMarshalerWithSchema(schema, ReaderWithContext(ctx, f), opts...)
+	structs, err := m.Unmarshal()
+	var pe ParseErrors
+	if err != nil && (!m.Lazy || !errors.As(err, &pe)) {
+		return fileResult{err: err, fatal: true}
+	}
+	return fileResult{structs: structs, err: err}
+}
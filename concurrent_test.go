@@ -0,0 +1,91 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeShards(t *testing.T, shards ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := []string{}
+	for i, shard := range shards {
+		path := filepath.Join(dir, fmt.Sprintf("shard-%d.csv", i))
+		if err := os.WriteFile(path, []byte("FIELD_0,FIELD_1,FIELD_2,FIELD_3\n"+shard), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestUnmarshalConcurrent(t *testing.T) {
+	shards := []string{}
+	want := []interface{}{}
+	for i := 0; i < 20; i++ {
+		shards = append(shards, fmt.Sprintf("a,%d,true,1.5\nb,%d,false,2.5\n", 2*i, 2*i+1))
+		want = append(want,
+			TestStruct{Field0: "a", Field1: 2 * i, Field2: true, Field3: 1.5},
+			TestStruct{Field0: "b", Field1: 2*i + 1, Field2: false, Field3: 2.5})
+	}
+	paths := writeShards(t, shards...)
+	result, err := UnmarshalConcurrent(context.Background(), paths, TestStruct{}, 4, FailFast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+}
+
+func TestUnmarshalConcurrentErrors(t *testing.T) {
+	paths := writeShards(t, "a,1,true,1.5\n", "b,x,true,1.5\n", "c,3,true,1.5\n")
+	paths = append(paths, filepath.Join(filepath.Dir(paths[0]), "missing.csv"))
+
+	_, err := UnmarshalConcurrent(context.Background(), paths, TestStruct{}, 2, FailFast)
+	var fe *FileError
+	if !errors.As(err, &fe) || fe.Path != paths[1] {
+		t.Errorf("wrong error - want: *FileError of %s, got: %v", paths[1], err)
+	}
+
+	result, err := UnmarshalConcurrent(context.Background(), paths, TestStruct{}, 2, CollectFileErrors)
+	want := []interface{}{
+		TestStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5},
+		TestStruct{Field0: "c", Field1: 3, Field2: true, Field3: 1.5},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	fileErrors, ok := err.(FileErrors)
+	if !ok || len(fileErrors) != 2 || fileErrors[0].Path != paths[1] || !errors.Is(fileErrors[1], os.ErrNotExist) {
+		t.Errorf("wrong errors - want: errors of %s and %s, got: %v", paths[1], paths[3], err)
+	}
+
+	// row errors of a Lazy Marshaler keep the valid rows of the file
+	lazy := func(m *Marshaler) { m.Lazy = true }
+	result, err = UnmarshalConcurrent(context.Background(), paths[:3], TestStruct{}, 2, FailFast, lazy)
+	if len(result) != 2 {
+		t.Errorf("wrong number of structs - want: %d, got: %d", 2, len(result))
+	}
+	var pe ParseErrors
+	if fileErrors, ok := err.(FileErrors); !ok || len(fileErrors) != 1 || !errors.As(fileErrors[0], &pe) || pe[0].Line != 2 {
+		t.Fatalf("wrong errors - want: ParseErrors of %s, got: %v", paths[1], err)
+	}
+	if fe := pe[0].Err.(*FieldError); fe.Source != paths[1] {
+		t.Errorf("wrong source - want: %s, got: %s", paths[1], fe.Source)
+	}
+}
+
+func TestUnmarshalConcurrentCancel(t *testing.T) {
+	paths := writeShards(t, "a,1,true,1.5\n", "b,2,true,1.5\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := UnmarshalConcurrent(ctx, paths, TestStruct{}, 2, CollectFileErrors); !errors.Is(err, context.Canceled) {
+		t.Errorf("wrong error - want: %s, got: %v", context.Canceled, err)
+	}
+}
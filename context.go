@@ -0,0 +1,41 @@
+package csv
+
+import (
+	"context"
+	"io"
+)
+
+// contextReader fails with the error of ctx as soon as ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// ReaderWithContext returns a reader that fails with the error of ctx, e.g. context.Canceled,
+// as soon as ctx is done. Errors of r after ctx is done, e.g. of an http.Response.Body whose
+// request was canceled, are replaced by the error of ctx as well.
+func ReaderWithContext(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := cr.r.Read(p)
+	if err != nil && err != io.EOF {
+		if ctxErr := cr.ctx.Err(); ctxErr != nil {
+			return n, ctxErr
+		}
+	}
+	return n, err
+}
+
+// UnmarshalContext parses a csv file like Unmarshal, reading the input with ReaderWithContext.
+// If ctx is done, the parse stops with a ReadError wrapping the error of ctx, also with Lazy.
+func (m *Marshaler) UnmarshalContext(ctx context.Context) ([]interface{}, error) {
+	r := m.source.r
+	m.source.r = ReaderWithContext(ctx, r)
+	defer func() { m.source.r = r }()
+	return m.Unmarshal()
+}
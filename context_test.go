@@ -0,0 +1,59 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// cancelingReader reads at most n bytes of r, then cancels the request and fails like the body
+// of a canceled http request.
+type cancelingReader struct {
+	r      *strings.Reader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (cr *cancelingReader) Read(p []byte) (int, error) {
+	if cr.n == 0 {
+		cr.cancel()
+		return 0, errors.New("read on closed response body")
+	}
+	if len(p) > cr.n {
+		p = p[:cr.n]
+	}
+	n, err := cr.r.Read(p)
+	cr.n -= n
+	return n, err
+}
+
+func TestUnmarshalContext(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,false,2.5\nc,3,true,3.5\n"
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &cancelingReader{r: strings.NewReader(data), n: 50, cancel: cancel}
+	m, err := NewMarshaler(TestStruct{}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	m.ReturnPartialOnError = true
+	result, err := m.UnmarshalContext(ctx)
+	var re *ReadError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &re) {
+		t.Fatalf("wrong error - want: ReadError with %s, got: %v", context.Canceled, err)
+	}
+	if len(result) != 1 || re.Line != 3 {
+		t.Errorf("wrong partial result - want: 1 record and error on line 3, got: %d records, error on line %d", len(result), re.Line)
+	}
+
+	// a done context fails the first read
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	if _, err := m.UnmarshalContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("wrong error - want: %s, got: %v", context.Canceled, err)
+	}
+}
@@ -1,13 +1,20 @@
 package csv
 
 import (
+	"bufio"
+	"bytes"
+	"encoding"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/oleiade/reflections"
 )
@@ -17,115 +24,1386 @@ var (
 	ErrNoValidRecords     = errors.New("no valid records found")
 	ErrHeaderNotComplete  = errors.New("header not complete")
 	ErrUnsupportedCSVType = errors.New("unsupported csv type")
+	ErrErrorRateExceeded  = errors.New("error rate limit exceeded")
+	ErrMultipleRecords    = errors.New("more than one record found")
+	ErrHeaderOrder        = errors.New("wrong header order")
+	ErrMissingCell        = errors.New("missing cell")
+	ErrExtraColumns       = errors.New("more columns than header")
+	ErrAliasConflict      = errors.New("header contains alias and canonical name")
+	ErrMissingHeader      = errors.New("missing header")
+	ErrUnknownColumn      = errors.New("unknown column")
+	ErrInvalidHeaderCell  = errors.New("invalid header cell")
+	ErrShortRow           = errors.New("fewer columns than header")
 )
 
 // Marshaler reads a csv file and unmarshalls it to an endpoint struct.
+//
+// With Lazy a row with an unterminated quote only loses its first line, the lines after it are
+// read as rows again. A header that cannot be read is returned as error.
+//
+// ValidateHeaderCells checks the header cells that bind to no field after MapHeader, aliases and
+// fuzzy matching, an error fails the header with ErrInvalidHeaderCell. The default
+// RejectNumericHeaderCell rejects a data row swapped with the header, but also a header with an
+// unrelated numeric column like A,2024. Set it to nil for numeric column names. With
+// IgnoreExtraColumns the cells without field are not checked.
+//
+// DetectTrailingEmptyColumn drops a last column that is empty in the header, or one column after
+// the header, and in the first TrailingColumnRows data rows, e.g. of a delimiter at the end of
+// every line. It is recorded as a single ErrTrailingEmptyColumn warning in the Report instead of
+// failing the rows. Preview and TypeCheck ignore it.
+//
+// The header name of a tag without name, e.g. `csv:",default=1"`, falls back to the field name.
+// Such fields are optional unless RequireUntagged is set: without their column they keep the zero
+// value, or the prototype value with UsePrototypeDefaults. Fields with a named tag always require a
+// column and fields ignored with `csv:"-"` never do, with or without RequireUntagged.
 type Marshaler struct {
-	Reader         *csv.Reader
-	Lazy           bool // if true, marshaler does not exit on first cvs.ParseError but continues and append all errors
-	fieldInfos     fieldInfos
-	endPointStruct interface{}
-	errors         ParseErrors
+	Reader                    *csv.Reader
+	Lazy                      bool                  // if true, marshaler does not exit on first cvs.ParseError but continues and append all errors
+	UsePrototypeDefaults      bool                  // if true, records start as copy of the endpoint struct instead of its zero value and empty cells keep the copied value
+	ErrorRateLimit            float64               // if > 0, abort with an ErrorRateError as soon as the ratio of failed to read data rows exceeds the limit
+	ErrorRateMinRows          int                   // minimum number of data rows read before ErrorRateLimit is evaluated
+	RequireHeaderOrder        bool                  // if true, the columns have to appear in the order of HeaderOrder
+	HeaderOrder               []string              // expected order of the csv header names, defaults to the order of the struct fields
+	ErrorOnMissingCells       bool                  // if true, records of variable width files (Reader.FieldsPerRecord < 0) without a cell for a field are invalid
+	IgnoreExtraColumns        bool                  // if true, data rows with more columns than the header are decoded and recorded as warning in the Report instead of failing with csv.ErrFieldCount
+	ErrorOnAliasConflict      bool                  // if true, a header containing an old name of WithHeaderAliases and its canonical name is invalid
+	InferTypes                bool                  // if true, fields of type interface{} store cells as bool, int64, float64 or string, e.g. true, 1 and 1.0 as bool, int64 and float64; if false they store the string
+	ReturnPartialOnError      bool                  // if true, the records decoded before a fatal error are returned together with the error; they are incomplete if err != nil
+	OnConversionError         ConversionErrorAction // action for cells that cannot be converted to fields without onerror tag option, defaults to FailOnError
+	CaptureRawOnError         bool                  // if true, the FieldError of a failed row carries the raw line of the record as Raw
+	MaxRawSize                int                   // maximum number of bytes of Raw, defaults to 1024
+	BackslashEscapes          bool                  // if true, the escapes \t, \n, \r and \\ in cells are decoded, for the tab-separated files of NewTSVMarshaler
+	DelimiterString           string                // if it has more than one rune, e.g. "||", cells are separated by it instead of Reader.Comma; cells are taken literally without quoting and records end at the end of the line. A single rune, e.g. ";", is used as Reader.Comma
+	PostDecode                PostDecoder           // if not nil, called with a pointer to every decoded record before it is kept; it may modify the record, an error rejects it with column -1
+	Metrics                   Metrics               // notified about rows, bytes and duration, defaults to a no-op implementation
+	ValidateHeaderCells       HeaderCellValidator   // checks the header cells that bind to no field, defaults to RejectNumericHeaderCell, nil disables the check
+	DetectTrailingEmptyColumn bool                  // if true, a trailing empty column is dropped and recorded as ErrTrailingEmptyColumn warning
+	DropTrailingEmptyColumn   bool                  // like DetectTrailingEmptyColumn, but the column is dropped without warning
+	TrailingColumnRows        int                   // number of data rows checked for a trailing empty column, defaults to 100
+	WarningsAsErrors          bool                  // if true, the Warnings of the Report are errors: warnings of a data row fail the row, the others the parse
+	RequireUntagged           bool                  // if true, fields of tags without header name require a column like named fields
+	fieldInfos                fieldInfos            // fieldInfos decoded by Unmarshal
+	allFieldInfos             fieldInfos            // fieldInfos of all fields of the endpoint struct
+	ignored                   map[string]bool       // names of the fields excluded with Ignore, a later Select keeps them excluded
+	endPointStruct            interface{}
+	errors                    ParseErrors
+	decoders                  map[string]FieldDecoder
+	converters                map[reflect.Type]func(string) (interface{}, error)
+	header                    []string
+	columns                   map[string]int // position of the first column per header name
+	report                    Report
+	dedupField                string
+	dedupKeep                 Keep
+	source                    *replayReader
+	lines                     *lineReader
+	delimiterResolved         bool // if true, DelimiterString is applied, see resolveDelimiter
+	trailingWidth             int  // width of the records with a trailing empty column, 0 if there is none
+	tsv                       bool // if true, the file is tab-separated without quoting, see NewTSVMarshaler
+	headerMap                 map[string]string
+	aliases                   map[string]string // old header name to canonical csv tag name
+	label                     string            // source label of WithSource
+	fuzzy                     bool              // if true, columns are matched with WithFuzzyHeaderMatch
+	preamblePrefix            string            // prefix of the preamble lines of WithPreamble
+	preamble                  []string          // preamble lines before the header without prefix
+	columnStats               bool              // if true, the Report contains ColumnStats
+	fixedWidth                bool              // if true, the fields have cols and the file has no header
+	where                     []condition       // conditions of WhereColumn
+	line                      int               // number of records read, the header included
+	recordLine                int               // input line the current record starts on
+	headerLine                int               // input line of the header
+	headerWidth               int               // number of cells of the header record
+	current                   interface{}
+	streamErr                 error
+	streamSeen                map[string]bool // DedupBy keys of the records returned by Next
+	offset                    int64
+	streamStart               time.Time
+	start                     time.Time     // start of the current parse
+	deadline                  time.Duration // deadline of WithDeadline
+	ignoreUnknownKeys         bool          // if true, UnmarshalVertical ignores keys without field
 }
 
-// NewMarshaler returns a new Marshaler
-func NewMarshaler(endPointStruct interface{}, r io.Reader) (*Marshaler, error) {
-	fieldInfos, err := createFieldInfos(endPointStruct)
+// Keep defines which record is kept if DedupBy detects a duplicate.
+type Keep int
+
+const (
+	KeepFirst Keep = iota // keep the first record and drop the duplicates
+	KeepLast              // replace the first record by the last duplicate
+)
+
+// Report summarizes the last Unmarshal.
+type Report struct {
+	Source          string                  // label of WithSource
+	Rows            int                     // number of data rows read
+	FailedRows      int                     // number of data rows that could not be decoded
+	Duplicates      int                     // number of records dropped or replaced by DedupBy
+	ColumnErrors    map[string]int          // number of errors per csv header name
+	ExtraColumnRows int                     // number of data rows with more columns than the header, see IgnoreExtraColumns
+	ZeroedCells     int                     // number of cells that could not be converted and left their field zero, see ZeroOnError
+	SkippedRows     int                     // number of data rows dropped because of a conversion error, see SkipRowOnError
+	FilteredRows    int                     // number of data rows skipped by WhereColumn, they are not counted in Rows
+	Warnings        Warnings                // non-fatal notices, e.g. ErrExtraColumns for rows with extra columns, see WarningsAsErrors
+	ColumnStats     map[string]*ColumnStats // statistics per csv header name, only with WithColumnStats
+	Aliases         map[string]string       // old header names of WithHeaderAliases found in the header with their canonical names
+	FuzzyMatches    map[string]string       // columns matched by WithFuzzyHeaderMatch with their csv tag names
+	ColumnCountRows int                     // number of data rows that failed with a ColumnCountError, they are counted in FailedRows too
+}
+
+var (
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// FieldDecoder decodes a raw cell. It receives the partially decoded endpoint struct, with all
+// fields set that have no FieldDecoder, and returns the value for the field.
+type FieldDecoder func(raw string, partial interface{}) (interface{}, error)
+
+// PostDecoder is called with a pointer to a decoded endpoint struct and its line.
+type PostDecoder func(ptr interface{}, line int) error
+
+// HeaderCellValidator checks a single cell of the header, see RejectNumericHeaderCell.
+type HeaderCellValidator func(cell string) error
+
+// NewMarshaler returns a new Marshaler configured with opts
+func NewMarshaler(endPointStruct interface{}, r io.Reader, opts ...Option) (*Marshaler, error) {
+	schema, err := NewSchema(endPointStruct)
 	if err != nil {
 		return nil, err
 	}
-	cr := csv.NewReader(r)
-	return &Marshaler{
-		Reader:         cr,
-		fieldInfos:     fieldInfos,
-		endPointStruct: endPointStruct,
-		errors:         ParseErrors{},
-	}, nil
+	return NewMarshalerWithSchema(schema, r, opts...), nil
 }
 
-// Unmarshal parses a csv file and stores its value to a list of entpoint structs
+// Unmarshal parses a csv file and stores its value to a list of entpoint structs.
+// With ReturnPartialOnError the structs decoded before a fatal error are returned along with
+// the error, the list is incomplete in that case.
 func (m *Marshaler) Unmarshal() ([]interface{}, error) {
-	structs := *new([]interface{})
+	return m.unmarshal(m.Reader, -1)
+}
+
+// UnmarshalGrouped parses a csv file like Unmarshal and groups the records by the value of the
+// struct field fieldName, formatted like a Writer writes it, e.g. 1.5 for a float64 or with the
+// layout of the format option. The records of a group keep the file order, the groups have no
+// order like every map. Errors are returned like by Unmarshal.
+func (m *Marshaler) UnmarshalGrouped(fieldName string) (map[string][]interface{}, error) {
+	i := m.fieldInfos.index(fieldName)
+	if i < 0 {
+		return nil, fmt.Errorf("no csv field: %s", fieldName)
+	}
+	fieldInfo := m.fieldInfos[i]
+	if fieldInfo.isGlob() {
+		return nil, fmt.Errorf("cannot group by glob field: %s", fieldName)
+	}
+	structs, err := m.Unmarshal()
+	if structs == nil && err != nil {
+		return nil, err
+	}
+	groups := map[string][]interface{}{}
+	w := &Writer{}
+	for _, s := range structs {
+		key, ferr := w.format(fieldInfo, reflect.ValueOf(s).FieldByName(fieldName))
+		if ferr != nil {
+			return nil, fmt.Errorf("cannot group by field %s: %w", fieldName, ferr)
+		}
+		groups[key] = append(groups[key], s)
+	}
+	return groups, err
+}
+
+// UnmarshalOne parses a csv file with exactly one data row and stores its value to dest,
+// which has to be a pointer to an endpoint struct.
+func (m *Marshaler) UnmarshalOne(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Type() != reflect.TypeOf(m.endPointStruct) {
+		return ErrWrongStructType
+	}
+	structs, err := m.unmarshal(m.Reader, 2)
+	if m.report.Rows > 1 {
+		return ErrMultipleRecords
+	}
+	if err != nil {
+		return err
+	}
+	if len(structs) == 0 {
+		return ErrNoValidRecords
+	}
+	v.Elem().Set(reflect.ValueOf(structs[0]))
+	return nil
+}
+
+// Preview parses the header and the first n data rows without consuming them, a subsequent
+// Unmarshal still parses the whole input. Errors of single rows are returned as errs,
+// fatal errors as err.
+func (m *Marshaler) Preview(n int) (header []string, rows []interface{}, errs ParseErrors, err error) {
+	r := m.replay()
+	defer m.source.rewind()
+	defer m.isolate()()
+	rows, err = m.unmarshal(r, n)
+	if len(m.errors) > 0 {
+		errs, err = m.errors, nil
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return m.header, rows, errs, nil
+}
+
+// TypeCheck parses the header and the first n data rows like Preview with all converters, defaults,
+// validators and the PostDecode hook without consuming them and returns only the errors of single
+// rows as errs, fatal errors as err. The decoded records are discarded.
+func (m *Marshaler) TypeCheck(n int) (errs ParseErrors, err error) {
+	r := m.replay()
+	defer m.source.rewind()
+	defer m.isolate()()
+	if err := m.unmarshalRecords(r, n, &discardRecords{typ: reflect.TypeOf(m.endPointStruct)}); err != nil {
+		return nil, err
+	}
+	if len(m.errors) == 0 {
+		return nil, nil
+	}
+	return m.errors, nil
+}
+
+// isolate prepares a parse of the replayed input without side effects on errors, metrics and report
+// of m and returns the function to restore them.
+func (m *Marshaler) isolate() func() {
+	saved, metrics, report, line, offset := m.errors, m.Metrics, m.report, m.line, m.offset
+	m.errors, m.Metrics = ParseErrors{}, noopMetrics{}
+	return func() { m.errors, m.Metrics, m.report, m.line, m.offset = saved, metrics, report, line, offset }
+}
+
+// ValidateHeaderFromReader reads the first line without consuming it and validates it
+// with ValidateHeader.
+func (m *Marshaler) ValidateHeaderFromReader() error {
+	r := m.replay()
+	defer m.source.rewind()
+	header, err := r.Read()
+	if err != nil {
+		return err
+	}
+	record, err := m.aliasHeader(m.mapHeader(header))
+	if err != nil {
+		return err
+	}
+	return validateHeader(m.fieldInfos, record, m.RequireUntagged)
+}
+
+// replay starts recording the input and returns a csv.Reader with the settings of m.Reader
+// for it, the input is rewound with m.source.rewind.
+func (m *Marshaler) replay() *csv.Reader {
+	m.source.record()
+	r := csv.NewReader(m.source)
+	m.resolveDelimiter()
+	if m.lines.split != nil {
+		lines := newLineReader(bufio.NewReader(m.source))
+		lines.split = m.lines.split
+		r = csv.NewReader(lines)
+	}
+	r.Comma = m.Reader.Comma
+	r.Comment = m.Reader.Comment
+	r.FieldsPerRecord = m.Reader.FieldsPerRecord
+	r.LazyQuotes = m.Reader.LazyQuotes
+	r.TrimLeadingSpace = m.Reader.TrimLeadingSpace
+	return r
+}
+
+// unmarshal parses the header and at most limit data rows from r, all rows if limit is negative.
+func (m *Marshaler) unmarshal(r *csv.Reader, limit int) ([]interface{}, error) {
+	sink := &SliceSink{}
+	if err := m.unmarshalSink(r, limit, sink); err != nil {
+		if m.ReturnPartialOnError || errors.Is(err, ErrDeadlineExceeded) {
+			return sink.Records, err
+		}
+		return nil, err
+	}
+	if len(m.errors) == 0 {
+		return sink.Records, nil
+	}
+	return sink.Records, m.errors
+}
+
+// UnmarshalSlice parses a csv file like Unmarshal, but decodes the records directly into dest,
+// which has to be a pointer to a slice of endpoint structs. This saves an allocation and a
+// copy per record.
+func (m *Marshaler) UnmarshalSlice(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice ||
+		v.Elem().Type().Elem() != reflect.TypeOf(m.endPointStruct) {
+		return ErrWrongStructType
+	}
+	v.Elem().SetLen(0)
+	_, err := m.unmarshalInto(&sliceRecords{slice: v.Elem()})
+	return err
+}
+
+// UnmarshalInto parses a csv file like Unmarshal and appends the records to dest, which has to be
+// a pointer to a slice of endpoint structs or of pointers to endpoint structs. It returns the
+// number of appended records, after a fatal error without ReturnPartialOnError none are kept.
+func (m *Marshaler) UnmarshalInto(dest interface{}) (int, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return 0, ErrWrongStructType
+	}
+	typ := reflect.TypeOf(m.endPointStruct)
+	records := &sliceRecords{slice: v.Elem(), n: v.Elem().Len(), base: v.Elem().Len()}
+	switch v.Elem().Type().Elem() {
+	case typ:
+	case reflect.PtrTo(typ):
+		records.ptr = true
+	default:
+		return 0, ErrWrongStructType
+	}
+	return m.unmarshalInto(records)
+}
+
+// unmarshalInto decodes all records into the slice of records and truncates it to the kept records.
+func (m *Marshaler) unmarshalInto(records *sliceRecords) (int, error) {
+	err := m.unmarshalRecords(m.Reader, -1, records)
+	if err != nil {
+		if !m.ReturnPartialOnError && !errors.Is(err, ErrDeadlineExceeded) {
+			records.n = records.base
+		}
+		records.slice.SetLen(records.n)
+		return records.n - records.base, err
+	}
+	records.slice.SetLen(records.n)
+	if len(m.errors) == 0 {
+		return records.n - records.base, nil
+	}
+	return records.n - records.base, m.errors
+}
+
+// unmarshalRecords reads at most limit data rows (all if limit < 0) into records. Only fatal
+// errors are returned, errors of single rows are collected in m.errors.
+func (m *Marshaler) unmarshalRecords(r *csv.Reader, limit int, records records) error {
+	if err := m.checkDedup(); err != nil {
+		return err
+	}
+	m.report = m.newReport()
+	m.line, m.offset = 0, 0
+	// a new slice, the errors of a previous parse may still be held by the caller
+	m.errors = ParseErrors{}
+	m.start = time.Now()
+	defer func() { m.Metrics.ObserveDuration(time.Since(m.start)) }()
+	seen := map[string]int{} // index of the kept record per DedupBy key
 
-	line := 0
-	for {
-		line++
-		var record stringSlice
-		record, err := m.Reader.Read()
+	for m.line == 0 || limit < 0 || m.report.Rows < limit {
+		sPtr, err := m.next(r, records.next, true)
+		if err == io.EOF {
+			break
+		}
+		if sPtr != nil {
+			if kerr := m.keep(sPtr, records, seen); kerr != nil {
+				return kerr
+			}
+		}
 		if err != nil {
-			if err == io.EOF {
-				break
+			return err
+		}
+	}
+	return nil
+}
+
+// keep adds the decoded struct to records, duplicates are handled according to DedupBy. An error
+// of records rejects the struct with the line of the record and column -1.
+func (m *Marshaler) keep(sPtr interface{}, records records, seen map[string]int) error {
+	i := -1
+	if m.dedupField != "" {
+		key := m.dedupKey(sPtr)
+		if j, ok := seen[key]; ok {
+			m.report.Duplicates++
+			if m.dedupKeep != KeepLast {
+				return nil
+			}
+			i = j
+		} else {
+			seen[key] = records.len()
+		}
+	}
+	if err := records.add(i); err != nil {
+		pe := &csv.ParseError{StartLine: m.recordLine, Line: m.recordLine, Column: -1, Err: &FieldError{Err: err}}
+		m.labelError(pe)
+		return pe
+	}
+	return nil
+}
+
+// checkDedup checks that the DedupBy field is decoded.
+func (m *Marshaler) checkDedup() error {
+	if m.dedupField != "" && m.fieldInfos.index(m.dedupField) < 0 {
+		return fmt.Errorf("DedupBy field %s is not decoded, it was removed by Select or Ignore", m.dedupField)
+	}
+	return nil
+}
+
+// dedupKey returns the string representation of the DedupBy field of the decoded struct sPtr.
+func (m *Marshaler) dedupKey(sPtr interface{}) string {
+	return fmt.Sprint(reflect.ValueOf(sPtr).Elem().FieldByName(m.dedupField).Interface())
+}
+
+// next reads the next line of r, resolves the header from the first line and decodes data rows
+// into a struct allocated by newRecord. It returns io.EOF at the end of the input and a nil
+// struct for the header and for rows with errors collected in m.errors: read errors are collected
+// with Lazy, decode errors if collectDecodeErrors is true. All other errors are returned.
+func (m *Marshaler) next(r *csv.Reader, newRecord func() interface{}, collectDecodeErrors bool) (interface{}, error) {
+	if m.line == 0 && m.fixedWidth {
+		if err := m.resolveHeader(m.fixedWidthHeader()); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.checkDeadline(r); err != nil {
+		return nil, err
+	}
+	if m.line == 0 && (m.DetectTrailingEmptyColumn || m.DropTrailingEmptyColumn) {
+		m.trailingWidth = 0
+		if r == m.Reader && !m.fixedWidth {
+			m.trailingWidth = m.detectTrailingColumn()
+		}
+	}
+	m.line++
+	resync := r == m.Reader && m.lines != nil
+	if resync {
+		m.resolveDelimiter()
+		m.lines.mark()
+	}
+	var record stringSlice
+	record, err := r.Read()
+	m.observeBytes(r)
+	record, err = m.trailingColumn(record, err)
+	if err != nil {
+		if err == io.EOF {
+			if m.columnStats {
+				if err := m.auditDecimals(); err != nil {
+					return nil, err
+				}
+			}
+			return nil, err
+		}
+		pe, ok := err.(*csv.ParseError)
+		if !ok { // errors of the underlying reader are always fatal
+			return nil, &ReadError{Line: m.line, Offset: m.inputOffset(r), Err: err}
+		}
+		raw := m.rawRecord(r, record) // before resync replays the lines
+		if resync {
+			replayed := m.lines.replayed
+			if m.Lazy {
+				m.lines.resync(pe)
+			}
+			// encoding/csv counts replayed lines twice
+			pe.StartLine -= replayed
+			pe.Line -= replayed
+		}
+		counted := m.columnCount(pe, record)
+		m.labelError(pe)
+		if !m.extraColumns(pe, record) {
+			if counted && !errors.Is(pe.Err, ErrExtraColumns) {
+				m.report.ColumnCountRows++
+			}
+			m.captureRaw(pe, raw)
+			return nil, m.readError(pe)
+		}
+	}
+	m.recordLine = m.inputLine(r)
+	if m.isHeader() { // first line contains header information
+		m.headerLine = m.recordLine
+		if resync {
+			m.collectPreamble()
+		}
+		err := m.resolveHeader(record)
+		m.headerWidth = len(record)
+		if err == nil && m.Reader.FieldsPerRecord == 0 {
+			m.Reader.FieldsPerRecord = len(record)
+		}
+		if err == nil {
+			err = m.trailingColumnWarning()
+		}
+		if pe, ok := err.(*csv.ParseError); ok {
+			if pe.Line > 0 {
+				pe.StartLine, pe.Line = m.headerLine, m.headerLine
+			}
+			m.labelError(pe)
+		}
+		return nil, err
+	}
+	if match, err := m.matchWhere(record); err != nil || !match {
+		return nil, err
+	}
+	m.report.Rows++
+	sPtr := newRecord()
+	perr := m.shortRow(record)
+	if perr == nil {
+		perr = m.decode(sPtr, record, m.recordLine)
+	}
+	if perr != nil && errors.Is(perr.Err, ErrRowSkipped) {
+		m.report.SkippedRows++
+		return nil, nil
+	}
+	if perr == nil && m.PostDecode != nil {
+		if err := m.PostDecode(sPtr, m.recordLine); err != nil {
+			perr = &csv.ParseError{Line: m.recordLine, Column: -1, Err: &FieldError{Err: err}}
+		}
+	}
+	m.Metrics.ObserveRow(perr == nil)
+	if perr != nil {
+		m.labelError(perr)
+		m.captureRaw(perr, m.rawRecord(r, record))
+		m.report.FailedRows++
+		if perr.Column >= 0 && perr.Column < len(m.header) {
+			m.report.ColumnErrors[m.header[perr.Column]]++
+		}
+		if !collectDecodeErrors {
+			return nil, perr
+		}
+		m.errors = append(m.errors, *perr)
+		return nil, m.checkErrorRate()
+	}
+	return sPtr, m.checkErrorRate()
+}
+
+// records collects the endpoint structs decoded by unmarshalRecords.
+type records interface {
+	next() interface{} // returns a pointer to a zero endpoint struct to decode the next record into
+	add(i int) error   // keeps the last decoded record, it replaces record i if i >= 0
+	len() int          // number of kept records
+}
+
+// discardRecords decodes every record into the same struct and keeps none.
+type discardRecords struct {
+	typ reflect.Type
+	ptr reflect.Value
+	n   int
+}
+
+func (r *discardRecords) next() interface{} {
+	if !r.ptr.IsValid() {
+		r.ptr = reflect.New(r.typ)
+	}
+	r.ptr.Elem().Set(reflect.Zero(r.typ))
+	return r.ptr.Interface()
+}
+
+func (r *discardRecords) add(i int) error {
+	if i < 0 {
+		r.n++
+	}
+	return nil
+}
+
+func (r *discardRecords) len() int {
+	return r.n
+}
+
+// sliceRecords decodes the records directly into the elements of a slice of endpoint structs.
+// The element after the n kept records is reused until a record is kept. The slice is grown in
+// chunks up to its capacity and truncated to the kept records at the end.
+type sliceRecords struct {
+	slice reflect.Value
+	n     int  // length of the slice with the kept records
+	base  int  // length of the slice before decoding
+	ptr   bool // if true, the slice elements are pointers to endpoint structs
+}
+
+func (r *sliceRecords) next() interface{} {
+	if r.slice.Len() == r.n {
+		r.grow()
+	}
+	elem := r.slice.Index(r.n)
+	if r.ptr {
+		elem.Set(reflect.New(elem.Type().Elem()))
+		return elem.Interface()
+	}
+	elem.Set(reflect.Zero(elem.Type()))
+	return elem.Addr().Interface()
+}
+
+// grow extends the slice to its capacity, a full slice is copied to one with twice the capacity.
+func (r *sliceRecords) grow() {
+	if r.slice.Len() < r.slice.Cap() {
+		r.slice.SetLen(r.slice.Cap())
+		return
+	}
+	size := 2*r.slice.Len() + 16
+	grown := reflect.MakeSlice(r.slice.Type(), size, size)
+	reflect.Copy(grown, r.slice)
+	r.slice.Set(grown)
+}
+
+func (r *sliceRecords) add(i int) error {
+	if i >= 0 {
+		r.slice.Index(i).Set(r.slice.Index(r.n))
+		return nil
+	}
+	r.n++
+	return nil
+}
+
+func (r *sliceRecords) len() int {
+	return r.n
+}
+
+// resolveHeader sets the field positions from the header record.
+func (m *Marshaler) resolveHeader(header []string) error {
+	if err := m.checkDecodable(); err != nil {
+		return err
+	}
+	record, err := m.aliasHeader(m.mapHeader(header))
+	if err != nil {
+		return err
+	}
+	match := m.allFieldInfos.matcher().match(record)
+	if err := m.validateHeaderCells(record, match); err != nil {
+		return err
+	}
+	columns := record.index()
+	var missing error // first missing part of a composite field
+	// clear the positions of a previous file
+	for i := range m.fieldInfos {
+		m.fieldInfos[i].position = -1
+	}
+	for i, fieldInfo := range m.fieldInfos {
+		if _, ok := fieldInfo.pinned(); ok {
+			if err := m.fieldInfos[i].resolvePinned(record); err != nil {
+				return &csv.ParseError{Line: 1, Err: err}
+			}
+			continue
+		}
+		if fieldInfo.isGroup() {
+			m.fieldInfos[i].resolveGroup(match.columns[fieldInfo.fieldName])
+			continue
+		}
+		if fieldInfo.isGlob() {
+			m.fieldInfos[i].resolveGlob(match.columns[fieldInfo.fieldName])
+			continue
+		}
+		if fieldInfo.isComposite() {
+			if part := m.fieldInfos[i].resolveComposite(columns); part != "" && missing == nil {
+				missing = fmt.Errorf("%w: no column %s of %s", ErrHeaderNotComplete, part, fieldInfo.headerName)
 			}
-			if !m.Lazy {
-				return nil, err
+			continue
+		}
+		if index, ok := columns[fieldInfo.headerName]; ok {
+			m.fieldInfos[i].position = index
+		}
+	}
+	if !m.fieldInfos.isComplete(m.RequireUntagged) {
+		if m.looksLikeData(record, match) {
+			return &csv.ParseError{Line: 1, Err: fmt.Errorf("%w, first line looks like data: %s", ErrMissingHeader, strings.Join(header, ","))}
+		}
+		if missing != nil {
+			return &csv.ParseError{Err: missing}
+		}
+		return &csv.ParseError{Err: ErrHeaderNotComplete}
+	}
+	for _, fieldInfo := range m.fieldInfos {
+		if err := fieldInfo.checkArrayLength(); err != nil {
+			return &csv.ParseError{Err: err}
+		}
+	}
+	m.header, m.columns = record, columns
+	if m.RequireHeaderOrder {
+		return m.checkHeaderOrder()
+	}
+	return nil
+}
+
+// mapHeader applies the header mapping of MapHeader.
+func (m *Marshaler) mapHeader(header []string) stringSlice {
+	record := make(stringSlice, len(header))
+	for i, name := range header {
+		if mapped, ok := m.headerMap[name]; ok {
+			name = mapped
+		}
+		record[i] = name
+	}
+	return record
+}
+
+// validateHeaderCells checks the cells of the header record that bind to no field with
+// ValidateHeaderCells. With IgnoreExtraColumns such cells are accepted as extra columns and not
+// checked. A header that looks like a data row is an ErrMissingHeader instead.
+func (m *Marshaler) validateHeaderCells(record []string, match headerMatch) error {
+	if m.ValidateHeaderCells == nil || m.fixedWidth || m.IgnoreExtraColumns {
+		return nil
+	}
+	pinned := m.allFieldInfos.pinnedColumns()
+	for i, cell := range record {
+		if pinned[i] || match.bound[i] {
+			continue
+		}
+		if err := m.ValidateHeaderCells(cell); err != nil {
+			if m.looksLikeData(record, match) {
+				return &csv.ParseError{Line: 1, Err: fmt.Errorf("%w, first line looks like data: %s", ErrMissingHeader, strings.Join(record, ","))}
 			}
-			if pe, ok := err.(*csv.ParseError); ok {
-				m.errors = append(m.errors, *pe)
+			return &csv.ParseError{Line: 1, Column: i, Err: fmt.Errorf("%w %q: %s", ErrInvalidHeaderCell, cell, err)}
+		}
+	}
+	return nil
+}
+
+// RejectNumericHeaderCell is the default of ValidateHeaderCells, it rejects cells that are
+// numbers, e.g. of a data row swapped with the header. Extra numeric columns of a valid header,
+// e.g. 2024 in A,2024, are rejected as well, see ValidateHeaderCells for the ways to accept them.
+func RejectNumericHeaderCell(cell string) error {
+	f, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	if err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return errors.New("header cell is a number")
+	}
+	return nil
+}
+
+// looksLikeData guesses if the header record is a data row: none of its cells is a csv header name of
+// the endpoint struct and at least half of them are empty or numbers and booleans for a struct with
+// fields of these kinds.
+func (m *Marshaler) looksLikeData(record []string, match headerMatch) bool {
+	numbers, bools := false, false
+	for _, fieldInfo := range m.fieldInfos {
+		switch fieldInfo.kind {
+		case reflect.Bool:
+			bools = true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			numbers = true
+		}
+	}
+	data := 0
+	for i, cell := range record {
+		if match.bound[i] {
+			return false
+		}
+		_, err := strconv.ParseFloat(cell, 64)
+		isNumber := err == nil
+		_, err = strconv.ParseBool(cell)
+		isBool := err == nil && !isNumber
+		if len(cell) == 0 || numbers && isNumber || bools && isBool {
+			data++
+		}
+	}
+	return data > 0 && 2*data >= len(record)
+}
+
+// aliasHeader renames the old header names of the aliases set with WithHeaderAliases to
+// their canonical names and records the used aliases in the report. If the canonical name
+// is present as well, the old column keeps its name or with ErrorOnAliasConflict an error
+// is returned. Afterwards the columns of WithFuzzyHeaderMatch are renamed.
+func (m *Marshaler) aliasHeader(record stringSlice) (stringSlice, error) {
+	if len(m.aliases) == 0 {
+		return m.fuzzyHeader(record)
+	}
+	columns := record.index()
+	for i, name := range record {
+		canonical, ok := m.aliases[name]
+		if !ok {
+			continue
+		}
+		if _, ok := columns[canonical]; ok {
+			if m.ErrorOnAliasConflict {
+				return nil, &csv.ParseError{Line: 1, Column: i, Err: fmt.Errorf("%w: %s and %s", ErrAliasConflict, name, canonical)}
 			}
 			continue
 		}
-		if line == 1 { // first line contains header information
-			for i, fieldInfo := range m.fieldInfos {
-				index := record.pos(fieldInfo.headerName)
-				if index >= 0 {
-					m.fieldInfos[i].position = index
-				}
+		if m.report.Aliases == nil {
+			m.report.Aliases = map[string]string{}
+		}
+		m.report.Aliases[name] = canonical
+		record[i] = canonical
+	}
+	return m.fuzzyHeader(record)
+}
+
+// checkHeaderOrder checks that the expected header names appear in the expected order.
+func (m *Marshaler) checkHeaderOrder() error {
+	expected := m.HeaderOrder
+	if expected == nil {
+		for _, fieldInfo := range m.fieldInfos.writable() {
+			if !fieldInfo.isGlob() && fieldInfo.position >= 0 {
+				expected = append(expected, fieldInfo.headerName)
 			}
-			if !m.fieldInfos.isComplete() {
-				return nil, &csv.ParseError{Err: ErrHeaderNotComplete}
-			}
-			continue
-		}
-		sPtr := reflect.New(reflect.TypeOf(m.endPointStruct)).Interface()
-		var (
-			value interface{}
-			rerr  error
-		)
-		for _, fieldInfo := range m.fieldInfos {
-			switch fieldInfo.kind {
-			case reflect.Bool:
-				value, rerr = strconv.ParseBool(record[fieldInfo.position])
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				value, rerr = strconv.Atoi(record[fieldInfo.position])
-			case reflect.Float32, reflect.Float64:
-				value, rerr = strconv.ParseFloat(record[fieldInfo.position], 64)
-			case reflect.String:
-				value = record[fieldInfo.position]
-			default:
-				rerr = ErrUnsupportedCSVType
-			}
-			if rerr != nil {
-				m.errors = append(m.errors, csv.ParseError{
-					Column: fieldInfo.position,
-					Line:   line,
-					Err:    rerr,
-				})
+		}
+	}
+	actual := []string{}
+	positions := stringSlice(expected).index()
+	for _, name := range m.header {
+		if _, ok := positions[name]; ok {
+			actual = append(actual, name)
+		}
+	}
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+	return &csv.ParseError{Line: 1, Err: fmt.Errorf("%w: expected %s, got %s", ErrHeaderOrder,
+		strings.Join(expected, ","), strings.Join(actual, ","))}
+}
+
+// inputLine returns the line of the input the record last read from r starts on, counting
+// comments and the lines of multi-line cells.
+func (m *Marshaler) inputLine(r *csv.Reader) int {
+	line, _ := r.FieldPos(0)
+	if r == m.Reader && m.lines != nil {
+		line -= m.lines.replayed // encoding/csv counts replayed lines twice
+	}
+	return line
+}
+
+// HeaderLine returns the line of the input the header of the last parsed file was found on,
+// comments before it included. It is 0 before the header is read and for fixed-width files.
+func (m *Marshaler) HeaderLine() int {
+	return m.headerLine
+}
+
+// isHeader checks if the current line is the header, fixed-width files have none.
+func (m *Marshaler) isHeader() bool {
+	return m.line == 1 && !m.fixedWidth
+}
+
+// labelError sets the label of WithSource as Source of the FieldError of pe, other errors are
+// wrapped in a FieldError.
+func (m *Marshaler) labelError(pe *csv.ParseError) {
+	if m.label == "" {
+		return
+	}
+	fieldError(pe).Source = m.label
+}
+
+// rawRecord returns the raw line of the record just read from r, at most MaxRawSize bytes. The
+// lines are only known for the Reader of m, for other readers the line is reconstructed from
+// the cells of record. It returns an empty string without CaptureRawOnError.
+func (m *Marshaler) rawRecord(r *csv.Reader, record []string) string {
+	if !m.CaptureRawOnError {
+		return ""
+	}
+	max := m.MaxRawSize
+	if max <= 0 {
+		max = defaultMaxRawSize
+	}
+	var raw []byte
+	if r == m.Reader && m.lines != nil {
+		raw = m.lines.lines
+	} else {
+		buf := &bytes.Buffer{}
+		w := csv.NewWriter(buf)
+		w.Comma = m.Reader.Comma
+		_ = w.Write(record)
+		w.Flush()
+		raw = buf.Bytes()
+	}
+	if len(raw) > max {
+		raw = raw[:max]
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(raw), "\n"), "\r")
+}
+
+// captureRaw sets raw as Raw of the FieldError of pe, see CaptureRawOnError.
+func (m *Marshaler) captureRaw(pe *csv.ParseError, raw string) {
+	if !m.CaptureRawOnError {
+		return
+	}
+	fieldError(pe).Raw = raw
+}
+
+// readError counts the row of the csv.ParseError pe as failed and returns pe, with Lazy it is
+// collected in m.errors instead. Errors of the header are always returned.
+func (m *Marshaler) readError(pe *csv.ParseError) error {
+	if !m.isHeader() {
+		m.report.Rows++
+		m.report.FailedRows++
+		m.Metrics.ObserveRow(false)
+	}
+	if !m.Lazy || m.isHeader() {
+		return pe
+	}
+	m.errors = append(m.errors, *pe)
+	return m.checkErrorRate()
+}
+
+// extraColumns reports if the csv.ParseError pe is caused by a data row with more columns than
+// the header that is decoded anyway because of IgnoreExtraColumns. The row is recorded as warning,
+// with WarningsAsErrors pe is replaced by the warning and the row fails.
+func (m *Marshaler) extraColumns(pe *csv.ParseError, record []string) bool {
+	if !m.IgnoreExtraColumns || m.isHeader() || !errors.Is(pe.Err, csv.ErrFieldCount) || len(record) <= len(m.header) {
+		return false
+	}
+	m.report.ExtraColumnRows++
+	if promoted := m.warn(csv.ParseError{
+		StartLine: pe.StartLine,
+		Line:      pe.Line,
+		Column:    len(m.header),
+		Err:       fmt.Errorf("%w: %d instead of %d", ErrExtraColumns, len(record), len(m.header)),
+	}); promoted != nil {
+		*pe = *promoted
+		return false
+	}
+	return true
+}
+
+// columnCount replaces the csv.ErrFieldCount of a data row by a ColumnCountError and reports if
+// pe was such an error.
+func (m *Marshaler) columnCount(pe *csv.ParseError, record []string) bool {
+	if m.isHeader() || pe.Err != csv.ErrFieldCount {
+		return false
+	}
+	expected := m.Reader.FieldsPerRecord
+	if expected <= 0 {
+		expected = m.headerWidth
+	}
+	pe.Err = &ColumnCountError{Expected: expected, Actual: len(record), HeaderLine: m.headerLine}
+	return true
+}
+
+// matchWhere checks the raw record against the conditions of WhereColumn, records that do not
+// match are counted as filtered. Conditions on a header name without column are an error.
+func (m *Marshaler) matchWhere(record []string) (bool, error) {
+	for _, c := range m.where {
+		position, ok := m.columns[c.header]
+		if !ok {
+			return false, &csv.ParseError{Line: m.recordLine, Column: -1, Err: fmt.Errorf("%w in WhereColumn: %s", ErrUnknownColumn, c.header)}
+		}
+		if position >= len(record) || record[position] != c.value {
+			m.report.FilteredRows++
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Headers returns the csv header names of the last parsed file after applying MapHeader,
+// nil before the header is read.
+func (m *Marshaler) Headers() []string {
+	if m.header == nil {
+		return nil
+	}
+	return append([]string{}, m.header...)
+}
+
+// Columns returns the zero based position of every csv header name of the last parsed file,
+// for duplicate names the first column. It is nil before the header is read.
+func (m *Marshaler) Columns() map[string]int {
+	if m.columns == nil {
+		return nil
+	}
+	columns := make(map[string]int, len(m.columns))
+	for name, i := range m.columns {
+		columns[name] = i
+	}
+	return columns
+}
+
+// newReport returns an empty Report for the source of m.
+func (m *Marshaler) newReport() Report {
+	report := Report{Source: m.label, ColumnErrors: map[string]int{}}
+	if m.columnStats {
+		report.ColumnStats = map[string]*ColumnStats{}
+	}
+	return report
+}
+
+// Report returns the Report of the last Unmarshal.
+func (m *Marshaler) Report() Report {
+	return m.report
+}
+
+// checkErrorRate returns an ErrorRateError if the ErrorRateLimit is exceeded.
+func (m *Marshaler) checkErrorRate() error {
+	if m.ErrorRateLimit <= 0 || m.report.Rows < m.ErrorRateMinRows {
+		return nil
+	}
+	rate := float64(m.report.FailedRows) / float64(m.report.Rows)
+	if rate <= m.ErrorRateLimit {
+		return nil
+	}
+	return &ErrorRateError{Rate: rate, Limit: m.ErrorRateLimit, ColumnErrors: m.report.ColumnErrors}
+}
+
+// decode converts a record to the endpoint struct sPtr points to. Fields with a registered FieldDecoder
+// are decoded last, so that the decoder can inspect all other fields.
+func (m *Marshaler) decode(sPtr interface{}, record []string, line int) *csv.ParseError {
+	if m.UsePrototypeDefaults {
+		reflect.ValueOf(sPtr).Elem().Set(reflect.ValueOf(m.endPointStruct))
+	}
+	decoderFields := []fieldInfo{}
+	for _, fieldInfo := range m.fieldInfos {
+		if _, ok := m.decoders[fieldInfo.headerName]; ok {
+			decoderFields = append(decoderFields, fieldInfo)
+			continue
+		}
+		if fieldInfo.isGlob() {
+			decode := m.decodeGlob
+			if fieldInfo.isGroup() {
+				decode = m.decodeGroup
+			}
+			value, perr := decode(fieldInfo, record, line)
+			if perr != nil {
+				return perr
+			}
+			if err := reflections.SetField(sPtr, fieldInfo.fieldName, value); err != nil {
+				return &csv.ParseError{Column: fieldInfo.position, Line: line, Err: newFieldError(fieldInfo, "", err)}
+			}
+			continue
+		}
+		if fieldInfo.position < 0 { // untagged field without column
+			continue
+		}
+		cell, err := m.cell(fieldInfo, record, fieldInfo.position)
+		if err == nil && m.UsePrototypeDefaults && len(cell) == 0 && !fieldInfo.isRaw() {
+			if m.columnStats {
+				m.observeCell(fieldInfo, record, nil, false)
+			}
+			continue
+		}
+		if err == nil {
+			err = fieldInfo.matchCell(cell)
+		}
+		var value interface{}
+		zeroed := false
+		if err == nil {
+			value, err = m.convert(fieldInfo, cell)
+			if err != nil {
+				value, zeroed, err = m.conversionError(fieldInfo, cell, line, err)
+			}
+		}
+		if err == nil {
+			err = reflections.SetField(sPtr, fieldInfo.fieldName, value)
+		}
+		if err == nil && fieldInfo.enum != nil && !zeroed {
+			err = fieldInfo.enum.check(value)
+		}
+		if err == nil && !zeroed {
+			err = fieldInfo.checkBounds(value)
+		}
+		if m.columnStats {
+			m.observeCell(fieldInfo, record, value, err != nil || zeroed)
+		}
+		if err != nil {
+			return &csv.ParseError{Column: fieldInfo.position, Line: line, Err: newFieldError(fieldInfo, cell, err)}
+		}
+	}
+	for _, fieldInfo := range decoderFields {
+		if fieldInfo.position < 0 {
+			continue
+		}
+		partial := reflect.ValueOf(sPtr).Elem().Interface()
+		cell, err := m.cell(fieldInfo, record, fieldInfo.position)
+		if err == nil {
+			err = fieldInfo.matchCell(cell)
+		}
+		var value interface{}
+		if err == nil {
+			value, err = m.decoders[fieldInfo.headerName](cell, partial)
+			if err != nil {
+				value, _, err = m.conversionError(fieldInfo, cell, line, err)
+			}
+		}
+		if err == nil && value != nil {
+			err = reflections.SetField(sPtr, fieldInfo.fieldName, value)
+		}
+		if err != nil {
+			return &csv.ParseError{Column: fieldInfo.position, Line: line, Err: newFieldError(fieldInfo, cell, err)}
+		}
+	}
+	return nil
+}
+
+// conversionError handles the error err of converting a cell of the field according to its
+// ConversionErrorAction. It returns the zero value of the field if it is zeroed or the error.
+// Zeroed cells are recorded as ErrZeroedCell warning of the cell on line.
+func (m *Marshaler) conversionError(fieldInfo fieldInfo, cell string, line int, err error) (interface{}, bool, error) {
+	switch m.onError(fieldInfo) {
+	case ZeroOnError:
+		zeroed := fmt.Errorf("%w: %s", ErrZeroedCell, err)
+		if promoted := m.warn(csv.ParseError{StartLine: line, Line: line, Column: fieldInfo.position,
+			Err: newFieldError(fieldInfo, cell, zeroed)}); promoted != nil {
+			return nil, false, zeroed
+		}
+		m.report.ZeroedCells++
+		return reflect.Zero(fieldInfo.typ).Interface(), true, nil
+	case SkipRowOnError:
+		return nil, false, fmt.Errorf("%w: %s", ErrRowSkipped, err)
+	}
+	return nil, false, err
+}
+
+// cell returns the cell of the record at position without the quotes removed by the unquote
+// option or the default value of the field if the cell is empty. Cells missing in short records of variable width files are empty unless
+// ErrorOnMissingCells is set. The cell is normalized according to the tag options of the field.
+func (m *Marshaler) cell(fieldInfo fieldInfo, record []string, position int) (string, error) {
+	cell := ""
+	if fieldInfo.isComposite() {
+		joined, err := m.joinCells(fieldInfo, record)
+		if err != nil {
+			return "", err
+		}
+		cell = joined
+	} else if position < len(record) {
+		cell = record[position]
+	} else if m.ErrorOnMissingCells {
+		return "", ErrMissingCell
+	}
+	if fieldInfo.isRaw() {
+		return cell, nil
+	}
+	if m.BackslashEscapes {
+		cell = unescape(cell)
+	}
+	if _, ok := fieldInfo.options["unquote"]; ok {
+		cell = unquote(cell)
+	}
+	if defaultValue, ok := fieldInfo.options["default"]; ok && len(cell) == 0 {
+		cell = defaultValue
+	}
+	return fieldInfo.normalize(cell), nil
+}
+
+// convert parses a cell with the converter registered for the field type, the type registered with
+// RegisterType, the built-in converters for standard library types, with encoding.TextUnmarshaler
+// or according to the kind of the field and converts it to the field type.
+func (m *Marshaler) convert(fieldInfo fieldInfo, cell string) (interface{}, error) {
+	if fieldInfo.isRaw() {
+		return reflect.ValueOf(cell).Convert(fieldInfo.typ).Interface(), nil
+	}
+	if converter, ok := m.converters[fieldInfo.typ]; ok {
+		return converter(cell)
+	}
+	if len(fieldInfo.layout) > 0 {
+		return time.Parse(fieldInfo.layout, cell)
+	}
+	if rt, ok := lookupType(fieldInfo.typ); ok {
+		return rt.parse(cell)
+	}
+	if converter, ok := builtinConverters[fieldInfo.typ]; ok {
+		return converter(cell)
+	}
+	if reflect.PtrTo(fieldInfo.typ).Implements(textUnmarshalerType) {
+		ptr := reflect.New(fieldInfo.typ)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(cell)); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+	var (
+		value interface{}
+		err   error
+	)
+	switch fieldInfo.kind {
+	case reflect.Bool:
+		if _, ok := fieldInfo.options["boolnum"]; ok {
+			value, err = parseBoolNum(cell)
+		} else {
+			value, err = strconv.ParseBool(cell)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fieldInfo.scale > 0 {
+			value, err = fieldInfo.parseScaled(cell)
+		} else {
+			value, err = strconv.ParseInt(cell, 10, fieldInfo.typ.Bits())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err = strconv.ParseUint(cell, 10, fieldInfo.typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		value, err = strconv.ParseFloat(cell, fieldInfo.typ.Bits())
+	case reflect.String:
+		value = cell
+	case reflect.Interface:
+		switch {
+		case fieldInfo.typ.NumMethod() > 0:
+			err = ErrUnsupportedCSVType
+		case m.InferTypes:
+			value = inferType(cell)
+		default:
+			value = cell
+		}
+	default:
+		err = ErrUnsupportedCSVType
+	}
+	if err != nil {
+		return nil, err
+	}
+	// convert the parsed value to the field type, e.g. int64 to int8
+	return reflect.ValueOf(value).Convert(fieldInfo.typ).Interface(), nil
+}
+
+// inferType returns the cell as bool if it is true or false in lower, upper or title case, as int64
+// if it is a decimal integer within the range of int64, as float64 if it is a finite decimal number,
+// e.g. "1.0", "1e3" or an integer overflowing int64, and as string otherwise, e.g. "", "1,5" or "NaN".
+func inferType(cell string) interface{} {
+	switch cell {
+	case "true", "TRUE", "True":
+		return true
+	case "false", "FALSE", "False":
+		return false
+	}
+	if strings.Trim(cell, "0123456789+-.eE") != "" {
+		return cell
+	}
+	if i, err := strconv.ParseInt(cell, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(cell, 64); err == nil {
+		return f
+	}
+	return cell
+}
+
+// checkDecodable returns an error naming the first field whose type can neither be decoded by a
+// FieldDecoder, a registered or built-in converter, encoding.TextUnmarshaler nor by its kind. It
+// runs when the header is resolved, because decoders and converters are registered after construction.
+func (m *Marshaler) checkDecodable() error {
+	for _, fieldInfo := range m.fieldInfos {
+		if _, ok := m.decoders[fieldInfo.headerName]; ok {
+			continue
+		}
+		if fieldInfo.isGroup() {
+			if err := m.checkGroupDecodable(fieldInfo); err != nil {
+				return err
+			}
+			continue
+		}
+		typ := fieldInfo.typ
+		if fieldInfo.isGlob() {
+			typ = typ.Elem()
+		}
+		if !m.decodable(typ) {
+			return fmt.Errorf("%w: field %s of type %s", ErrUnsupportedCSVType, fieldInfo.fieldName, fieldInfo.typ)
+		}
+	}
+	return nil
+}
+
+// decodable checks if convert supports the type typ.
+func (m *Marshaler) decodable(typ reflect.Type) bool {
+	if _, ok := m.converters[typ]; ok {
+		return true
+	}
+	if _, ok := lookupType(typ); ok {
+		return true
+	}
+	if _, ok := builtinConverters[typ]; ok {
+		return true
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+	if typ.Kind() == reflect.Interface {
+		return typ.NumMethod() == 0
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// RegisterFieldDecoder registers a FieldDecoder for the field with the given csv header name.
+// It is required for fields of non-empty interface types and takes precedence over the built-in conversion.
+func (m *Marshaler) RegisterFieldDecoder(headerName string, decoder FieldDecoder) error {
+	if !m.allFieldInfos.hasHeader(headerName) {
+		return fmt.Errorf("no field with csv tag: %s", headerName)
+	}
+	m.decoders[headerName] = decoder
+	return nil
+}
+
+// RegisterConverter registers a converter for all fields of type t. The converter has to be a
+// function of the form func(string) (T, error), where T is assignable to t or is interface{}.
+// Converters take precedence over the built-in conversion.
+func (m *Marshaler) RegisterConverter(t reflect.Type, converter interface{}) error {
+	fn := reflect.ValueOf(converter)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return fmt.Errorf("converter for %s must be of type func(string) (%s, error), got: %T", t, t, converter)
+	}
+	if c, ok := converter.(func(string) (interface{}, error)); ok {
+		m.converters[t] = c
+		return nil
+	}
+	ft := fn.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.In(0).Kind() != reflect.String ||
+		ft.NumOut() != 2 || ft.Out(1) != errorType || !ft.Out(0).AssignableTo(t) {
+		return fmt.Errorf("converter for %s must be of type func(string) (%s, error), got: %s", t, t, ft)
+	}
+	m.converters[t] = func(s string) (interface{}, error) {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(s).Convert(ft.In(0))})
+		err, _ := out[1].Interface().(error)
+		return out[0].Interface(), err
+	}
+	return nil
+}
+
+// Select restricts decoding to the struct fields with the given names, all other fields keep
+// their zero value and their columns are not required in the header. Fields excluded with
+// Ignore stay excluded, regardless of the order of the calls.
+func (m *Marshaler) Select(fieldNames ...string) error {
+	selected := fieldInfos{}
+	for _, fieldName := range fieldNames {
+		if m.allFieldInfos.index(fieldName) < 0 {
+			return fmt.Errorf("no csv field: %s", fieldName)
+		}
+	}
+	for _, fieldInfo := range m.allFieldInfos {
+		if m.ignored[fieldInfo.fieldName] {
+			continue
+		}
+		for _, fieldName := range fieldNames {
+			if fieldInfo.fieldName == fieldName {
+				selected = append(selected, fieldInfo)
 				break
 			}
-			reflections.SetField(sPtr, fieldInfo.fieldName, value)
 		}
-		// add value only if error is nil
-		if rerr == nil {
-			v := reflect.ValueOf(sPtr).Elem().Interface()
-			structs = append(structs, v)
+	}
+	m.fieldInfos = selected
+	return nil
+}
+
+// Ignore excludes the struct fields with the given names from decoding, they keep their
+// zero value and their columns are not required in the header.
+func (m *Marshaler) Ignore(fieldNames ...string) error {
+	for _, fieldName := range fieldNames {
+		if m.allFieldInfos.index(fieldName) < 0 {
+			return fmt.Errorf("no csv field: %s", fieldName)
 		}
 	}
-	if len(m.errors) == 0 {
-		return structs, nil
+	if m.ignored == nil {
+		m.ignored = map[string]bool{}
+	}
+	for _, fieldName := range fieldNames {
+		m.ignored[fieldName] = true
+	}
+	remaining := fieldInfos{}
+	for _, fieldInfo := range m.fieldInfos {
+		ignored := false
+		for _, fieldName := range fieldNames {
+			ignored = ignored || fieldInfo.fieldName == fieldName
+		}
+		if !ignored {
+			remaining = append(remaining, fieldInfo)
+		}
 	}
-	return structs, m.errors
+	m.fieldInfos = remaining
+	return nil
 }
 
-// ParseErrors is a slice of csv.ParseError
-type ParseErrors []csv.ParseError
+// MapHeader maps header names of the csv file to the csv tag names of the endpoint struct,
+// e.g. {"OLD_NAME": "FIELD_3"}.
+func (m *Marshaler) MapHeader(mapping map[string]string) error {
+	for _, headerName := range mapping {
+		if !m.allFieldInfos.hasHeader(headerName) {
+			return fmt.Errorf("no field with csv tag: %s", headerName)
+		}
+	}
+	for name, headerName := range mapping {
+		m.headerMap[name] = headerName
+	}
+	return nil
+}
 
-// Error returns te ParseErrors as string
-func (errs ParseErrors) Error() string {
-	s := ""
-	for _, err := range errs {
-		s = s + fmt.Sprintf("line:%d,position:%d,err:%s\n", err.Line, err.Column, err.Error)
+// DedupBy drops records with the same string representation of the struct field fieldName.
+// With KeepLast the first record is replaced in place by its last duplicate, which Next does not
+// support. The field has to be decoded, a parse fails if it is removed by Select or Ignore.
+func (m *Marshaler) DedupBy(fieldName string, keep Keep) error {
+	if m.allFieldInfos.index(fieldName) < 0 {
+		return fmt.Errorf("no csv field: %s", fieldName)
 	}
-	return s
+	m.dedupField = fieldName
+	m.dedupKeep = keep
+	return nil
 }
 
 // fieldInfo descripes the mapping between the endpointStruct end the header in a csv file.
@@ -134,70 +1412,424 @@ type fieldInfo struct {
 	headerName string
 	fieldName  string
 	kind       reflect.Kind
+	typ        reflect.Type
+	options    tagOptions
+	positions  []int          // positions of all matching columns of a glob field
+	keys       []string       // keys of the matching columns of a glob field
+	enum       *enum          // allowed values, nil without enum option
+	match      *regexp.Regexp // pattern of the match option
+	bounds     []bound        // bounds of the min and max options
+	scale      int            // fractional digits of the scale option, 0 without
+	layout     string         // time layout of the format option
+	parts      []string       // header names of a composite field
+	cols       []int          // one based first and last rune of the cols option of fixed-width files
+	group      fieldInfos     // fields of the element struct of a group field
+	groups     [][]int        // positions of the element fields of a group field by group
+	untagged   bool           // if true, the tag has no header name and headerName is the field name
 }
 
 type fieldInfos []fieldInfo
 
-// isComplete checks if the all field positions could be detected from the csv file.
-func (fieldInfos *fieldInfos) isComplete() bool {
+// isComplete checks if the all field positions could be detected from the csv file. Fields of
+// tags without header name may have no position unless requireUntagged is set.
+func (fieldInfos *fieldInfos) isComplete(requireUntagged bool) bool {
 	for _, fieldInfo := range *fieldInfos {
-		if fieldInfo.position < 0 {
+		if fieldInfo.position < 0 && !fieldInfo.isGlob() && (requireUntagged || !fieldInfo.untagged) {
 			return false
 		}
 	}
 	return true
 }
 
+// writable returns the fieldInfos without the readonly option and composite fields, they have
+// unique header names.
+func (fieldInfos fieldInfos) writable() fieldInfos {
+	var writable []fieldInfo
+	for _, fieldInfo := range fieldInfos {
+		if _, ok := fieldInfo.options["readonly"]; !ok && !fieldInfo.isComposite() {
+			writable = append(writable, fieldInfo)
+		}
+	}
+	return writable
+}
+
+// hasHeader checks if a fieldInfo with the csv header name exists.
+func (fieldInfos fieldInfos) hasHeader(headerName string) bool {
+	for _, fieldInfo := range fieldInfos {
+		if fieldInfo.headerName == headerName {
+			return true
+		}
+	}
+	return false
+}
+
+// headerMatcher matches header cells against the fields: the csv header names and the parts of
+// composite names are looked up in a set, only the glob and group patterns are matched per cell.
+type headerMatcher struct {
+	names    map[string]bool
+	patterns fieldInfos // glob and group fields
+}
+
+// matcher returns the headerMatcher of the fieldInfos, build it once per header.
+func (fieldInfos fieldInfos) matcher() headerMatcher {
+	hm := headerMatcher{names: make(map[string]bool, len(fieldInfos))}
+	for _, fieldInfo := range fieldInfos {
+		hm.names[fieldInfo.headerName] = true
+		for _, part := range fieldInfo.parts {
+			hm.names[part] = true
+		}
+		if fieldInfo.isGlob() {
+			hm.patterns = append(hm.patterns, fieldInfo)
+		}
+	}
+	return hm
+}
+
+// patternColumn is a header column matched by the pattern of a glob or group field.
+type patternColumn struct {
+	position      int
+	key           string // part matched by the * of a glob pattern
+	member, index int    // element field and group index of a group pattern
+}
+
+// headerMatch is the result of matching every cell of a header once against the fields.
+type headerMatch struct {
+	bound   []bool                     // cells bound to a field
+	columns map[string][]patternColumn // columns of the glob and group fields by field name
+}
+
+// match matches the cells of header in a single pass, the patterns are matched once per cell.
+func (hm headerMatcher) match(header []string) headerMatch {
+	match := headerMatch{bound: make([]bool, len(header)), columns: map[string][]patternColumn{}}
+	for i, name := range header {
+		match.bound[i] = hm.names[name]
+		for _, fieldInfo := range hm.patterns {
+			if fieldInfo.isGroup() {
+				if member, index, ok := fieldInfo.groupMatch(name); ok {
+					match.bound[i] = true
+					match.columns[fieldInfo.fieldName] = append(match.columns[fieldInfo.fieldName], patternColumn{position: i, member: member, index: index})
+				}
+			} else if key, ok := globMatch(fieldInfo.headerName, name); ok {
+				match.bound[i] = true
+				match.columns[fieldInfo.fieldName] = append(match.columns[fieldInfo.fieldName], patternColumn{position: i, key: key})
+			}
+		}
+	}
+	return match
+}
+
+// index returns the index of the fieldInfo for the struct field fieldName or -1.
+func (fieldInfos fieldInfos) index(fieldName string) int {
+	for i, fieldInfo := range fieldInfos {
+		if fieldInfo.fieldName == fieldName {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkFieldMap checks that the field names of a WithFieldMap are exported fields of the struct.
+func checkFieldMap(fieldNames []string, fieldMap map[string]string) error {
+	known := make(map[string]bool, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		known[fieldName] = true
+	}
+	unknown := []string{}
+	for fieldName := range fieldMap {
+		if !known[fieldName] {
+			unknown = append(unknown, fieldName)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("field map contains unknown fields: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
 // createFieldInfos creates the fieldInfos for a struct s.
-// Only information from the struct (headerName, fieldName and kind) is available,
+// Only information from the struct (headerName, fieldName, kind and type) is available,
 // all field positions are initialized with an invalid value of -1
 func createFieldInfos(s interface{}) (fieldInfos, error) {
-	if reflect.TypeOf(s).Kind() != reflect.Struct {
+	return createMappedFieldInfos(s, nil)
+}
+
+// createMappedFieldInfos creates the fieldInfos like createFieldInfos, fields without csv tag
+// take their tag from fieldMap, see WithFieldMap.
+func createMappedFieldInfos(s interface{}, fieldMap map[string]string) (fieldInfos, error) {
+	s = indirect(s)
+	if s == nil || reflect.TypeOf(s).Kind() != reflect.Struct {
 		return nil, ErrNoStruct
 	}
-	fieldInfos := []fieldInfo{}
-	headerNameMap := map[string]interface{}{} // to detect duplicate csv tag names
-	fieldNames, err := reflections.Fields(s)
+	fieldInfos := fieldInfos{}
+	headerNameMap := map[string]interface{}{} // to detect duplicate csv tag names of written fields
+	fieldNames, err := reflections.Fields(s)  // unexported fields are not returned
 	if err != nil {
 		return nil, err
 	}
+	if err := checkFieldMap(fieldNames, fieldMap); err != nil {
+		return nil, err
+	}
 	for _, fieldName := range fieldNames {
-		headerName, err := reflections.GetFieldTag(s, fieldName, "csv")
+		tag, err := reflections.GetFieldTag(s, fieldName, "csv")
 		if err != nil {
 			return nil, err
 		}
-		// csv fieldtags that contain a dash are ignored
-		if strings.Contains(headerName, "-") {
+		if mapped, ok := fieldMap[fieldName]; ok && len(tag) == 0 {
+			tag = mapped
+		}
+		field, _ := reflect.TypeOf(s).FieldByName(fieldName)
+		// embedded fields without csv tag, e.g. a sync.Mutex, are ignored
+		if field.Anonymous && len(tag) == 0 {
+			continue
+		}
+		if len(tag) == 0 {
+			return nil, fmt.Errorf("empty csv tag for field: %s", fieldName)
+		}
+		// like in encoding/json a single dash ignores the field, `csv:"-,"` is the header "-"
+		if tag == "-" {
 			continue
 		}
-		if _, ok := headerNameMap[headerName]; ok {
-			return nil, fmt.Errorf("duplicate csv tag name: %s", headerName)
+		headerName, options, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		// an empty header name with or without options falls back to the field name
+		untagged := len(headerName) == 0
+		if untagged {
+			headerName = fieldName
+		}
+		elemType := field.Type
+		group, err := parseGroup(headerName, field.Type, options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		if group != nil {
+			elemType = field.Type.Elem()
+		} else if _, ok := options["glob"]; ok {
+			if err := validateGlob(headerName, field.Type, options); err != nil {
+				return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+			}
+			elemType = field.Type.Elem()
+		} else if _, ok := options["ordered"]; ok {
+			return nil, fmt.Errorf("invalid csv tag for field %s: option ordered requires the glob option", fieldName)
+		}
+		if err := validateNormalization(elemType, options); err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		enum, err := parseEnum(elemType, options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		match, err := parseMatch(options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		scale, err := parseScale(elemType, options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		bounds, err := parseBounds(elemType, options, scale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		layout, err := parseLayout(elemType, options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		parts, err := parseComposite(headerName, options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		if err := validateOnError(options); err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		if err := validateBoolNum(elemType, options); err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		if err := validateRaw(elemType, options); err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		if err := validatePos(options, parts); err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		if err := validateEmptyQuoted(elemType, options); err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		cols, err := parseCols(options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		// several fields can decode the same column, but only one of them is written
+		if _, readonly := options["readonly"]; !readonly {
+			if _, ok := headerNameMap[headerName]; ok {
+				return nil, fmt.Errorf("duplicate csv tag name: %s, all but one field have to be readonly", headerName)
+			}
+			headerNameMap[headerName] = nil
 		}
-		headerNameMap[headerName] = nil
 		kind, err := reflections.GetFieldKind(s, fieldName)
 		if err != nil {
 			return nil, err
 		}
-		if len(headerName) == 0 {
-			return nil, fmt.Errorf("empty csv tag for field: %s", fieldName)
-		}
-		fieldInfos = append(fieldInfos, fieldInfo{
+		info := fieldInfo{
 			headerName: headerName,
 			fieldName:  fieldName,
 			position:   -1,
 			kind:       kind,
-		})
+			typ:        field.Type,
+			options:    options,
+			enum:       enum,
+			match:      match,
+			bounds:     bounds,
+			scale:      scale,
+			layout:     layout,
+			parts:      parts,
+			cols:       cols,
+			group:      group,
+			untagged:   untagged,
+		}
+		if err := validateDefault(info); err != nil {
+			return nil, fmt.Errorf("invalid csv tag for field %s: %s", fieldName, err)
+		}
+		fieldInfos = append(fieldInfos, info)
+	}
+	if _, err := fieldInfos.isFixedWidth(); err != nil {
+		return nil, err
 	}
 	return fieldInfos, nil
 }
 
-type stringSlice []string
+// ValidateHeader checks if header contains a column for every field of the struct s and
+// nothing else. The differences are returned as HeaderError. Fields of tags without header name
+// are optional like in a Marshaler without RequireUntagged.
+func ValidateHeader(s interface{}, header []string) error {
+	schema, err := NewSchema(s)
+	if err != nil {
+		return err
+	}
+	return schema.ValidateHeader(header)
+}
 
-func (s stringSlice) pos(item string) int {
-	for i, v := range s {
-		if item == v {
-			return i
+// Diff reads the header of r and returns the csv header names of the endpoint struct s without
+// column and the columns without field. The header is mapped with the aliases of opts like in
+// Unmarshal, so the diff shows what Unmarshal would decode. Duplicate columns are ignored.
+func Diff(s interface{}, r io.Reader, opts ...Option) (missingInFile, extraInFile []string, err error) {
+	m, err := NewMarshaler(s, r, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.resolveDelimiter()
+	header, err := m.Reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	record, err := m.aliasHeader(m.mapHeader(header))
+	if err != nil {
+		return nil, nil, err
+	}
+	var herr *HeaderError
+	if err := validateHeader(m.fieldInfos, record, m.RequireUntagged); errors.As(err, &herr) {
+		return herr.Missing, herr.Extra, nil
+	}
+	return nil, nil, nil
+}
+
+func validateHeader(fieldInfos fieldInfos, header stringSlice, requireUntagged bool) error {
+	herr := &HeaderError{}
+	seen := map[string]int{}
+	pinned := fieldInfos.pinnedColumns()
+	match := fieldInfos.matcher().match(header)
+	for i, name := range header {
+		seen[name]++
+		if seen[name] == 2 {
+			herr.Duplicates = append(herr.Duplicates, name)
+		}
+		if seen[name] == 1 && !pinned[i] && !match.bound[i] {
+			herr.Extra = append(herr.Extra, name)
 		}
 	}
-	return -1
+	missing := map[string]bool{}
+	for _, fieldInfo := range fieldInfos {
+		names := []string{fieldInfo.headerName}
+		if _, ok := fieldInfo.pinned(); ok {
+			continue
+		} else if fieldInfo.isComposite() {
+			names = fieldInfo.parts
+		} else if fieldInfo.isGlob() || fieldInfo.untagged && !requireUntagged {
+			continue
+		}
+		for _, name := range names {
+			if seen[name] == 0 && !missing[name] {
+				missing[name] = true
+				herr.Missing = append(herr.Missing, name)
+			}
+		}
+	}
+	if len(herr.Missing) == 0 && len(herr.Extra) == 0 && len(herr.Duplicates) == 0 {
+		return nil
+	}
+	return herr
+}
+
+// indirect dereferences a pointer to a struct. Nil pointers result in the zero
+// value of the struct, because only the type is needed.
+func indirect(s interface{}) interface{} {
+	t := reflect.TypeOf(s)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return s
+	}
+	v := reflect.ValueOf(s)
+	if v.IsNil() {
+		return reflect.Zero(t.Elem()).Interface()
+	}
+	return v.Elem().Interface()
+}
+
+// replayReader records the read bytes while recording, after rewinding the recorded
+// bytes are read again.
+type replayReader struct {
+	r         io.Reader
+	recorded  []byte
+	pos       int
+	recording bool
+}
+
+func (rr *replayReader) Read(p []byte) (int, error) {
+	if rr.pos < len(rr.recorded) {
+		n := copy(p, rr.recorded[rr.pos:])
+		rr.pos += n
+		if !rr.recording && rr.pos == len(rr.recorded) {
+			rr.recorded, rr.pos = nil, 0
+		}
+		return n, nil
+	}
+	n, err := rr.r.Read(p)
+	if rr.recording {
+		rr.recorded = append(rr.recorded, p[:n]...)
+		rr.pos += n
+	}
+	return n, err
+}
+
+// record starts recording, already recorded bytes are replayed first.
+func (rr *replayReader) record() {
+	rr.recording = true
+	rr.pos = 0
+}
+
+// rewind stops recording and replays the recorded bytes on the next reads.
+func (rr *replayReader) rewind() {
+	rr.recording = false
+	rr.pos = 0
+}
+
+type stringSlice []string
+
+// index returns the position of the first occurrence of every item.
+func (s stringSlice) index() map[string]int {
+	positions := make(map[string]int, len(s))
+	for i := len(s) - 1; i >= 0; i-- {
+		positions[s[i]] = i
+	}
+	return positions
 }
@@ -1,10 +1,18 @@
 package csv
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type TestStruct struct {
@@ -68,24 +76,28 @@ func TestCsvHeadersValidStructs(t *testing.T) {
 			headerName: "FIELD_0",
 			fieldName:  "Field0",
 			kind:       reflect.String,
+			typ:        reflect.TypeOf(""),
 		},
 		fieldInfo{
 			position:   -1,
 			headerName: "FIELD_1",
 			fieldName:  "Field1",
 			kind:       reflect.Int,
+			typ:        reflect.TypeOf(0),
 		},
 		fieldInfo{
 			position:   -1,
 			headerName: "FIELD_2",
 			fieldName:  "Field2",
 			kind:       reflect.Bool,
+			typ:        reflect.TypeOf(false),
 		},
 		fieldInfo{
 			position:   -1,
 			headerName: "FIELD_3",
 			fieldName:  "Field3",
 			kind:       reflect.Float64,
+			typ:        reflect.TypeOf(float64(0)),
 		},
 	}
 	generatedFieldInfos, err := createFieldInfos(good)
@@ -104,6 +116,142 @@ func TestCsvHeadersValidStructs(t *testing.T) {
 
 }
 
+type RawLevelStruct struct {
+	RawLevel string `csv:"LEVEL,readonly"`
+	Level    int    `csv:"LEVEL"`
+	Name     string `csv:"NAME"`
+}
+
+func TestUnmarshalMissingHeader(t *testing.T) {
+	var missingHeaderTests = map[string]struct {
+		s    interface{}
+		data string
+		err  error
+	}{
+		"data row":       {TestStruct{}, "string1,1,true,1.14\nstring2,2,false,2.14", ErrMissingHeader},
+		"empty cells":    {TestStruct{}, "string1,,,\nstring2,2,false,2.14", ErrMissingHeader},
+		"typo in header": {TestStruct{}, "FIELD_0,FIELD_1,FIELD_2,FIELD_X\nstring1,1,true,1.14", ErrHeaderNotComplete},
+		"unknown names":  {TestStruct{}, "a,b,c,d\nstring1,1,true,1.14", ErrHeaderNotComplete},
+		"mostly text":    {TestStruct{}, "string1,text,1,text\nstring2,2,false,2.14", ErrInvalidHeaderCell},
+		"only strings":   {NamedStringStruct{}, "CH,first\nDE,second", ErrHeaderNotComplete},
+		// the numbers are not data if the struct has no numeric fields, but no header names either
+		"numeric names": {NamedStringStruct{}, "1,2\nCH,first", ErrInvalidHeaderCell},
+	}
+	for name, test := range missingHeaderTests {
+		m, err := NewMarshaler(test.s, strings.NewReader(test.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Unmarshal(); !errors.Is(err, test.err) {
+			t.Errorf("wrong error for test '%s' - want: %s, got: %v", name, test.err, err)
+		}
+	}
+}
+
+func TestValidateHeaderCells(t *testing.T) {
+	rejectPadding := func(cell string) error {
+		if strings.TrimSpace(cell) != cell || strings.Contains(cell, `"`) {
+			return errors.New("padded or quoted")
+		}
+		return nil
+	}
+	tt := map[string]struct {
+		data     string
+		validate HeaderCellValidator
+		ignore   bool // IgnoreExtraColumns
+		column   int  // column of the ErrInvalidHeaderCell, -1 for success
+	}{
+		"numeric extra column": {
+			data:     "FIELD_0,FIELD_1,2024,FIELD_2,FIELD_3\na,1,x,true,1.5\n",
+			validate: RejectNumericHeaderCell,
+			column:   2,
+		},
+		"check disabled": {
+			data:   "FIELD_0,FIELD_1,2024,FIELD_2,FIELD_3\na,1,x,true,1.5\n",
+			column: -1,
+		},
+		"ignored extra column": {
+			data:     "FIELD_0,FIELD_1,2024,FIELD_2,FIELD_3\na,1,x,true,1.5\n",
+			validate: RejectNumericHeaderCell,
+			ignore:   true,
+			column:   -1,
+		},
+		"padded": {
+			data:     "FIELD_0, FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\n",
+			validate: rejectPadding,
+			column:   1,
+		},
+		"quoted": {
+			data:     "FIELD_0,FIELD_1,FIELD_2,\"\"\"FIELD_3\"\"\"\na,1,true,1.5\n",
+			validate: rejectPadding,
+			column:   3,
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.ValidateHeaderCells = tc.validate
+			m.IgnoreExtraColumns = tc.ignore
+			_, err = m.Unmarshal()
+			if tc.column < 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) || !errors.Is(err, ErrInvalidHeaderCell) || pe.Column != tc.column || pe.Line != 1 {
+				t.Errorf("wrong error - want: %s in column %d, got: %v", ErrInvalidHeaderCell, tc.column, err)
+			}
+		})
+	}
+}
+
+func TestReadonlyFields(t *testing.T) {
+	m, err := NewMarshaler(RawLevelStruct{}, strings.NewReader("NAME,LEVEL\na,01\nb,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		RawLevelStruct{RawLevel: "01", Level: 1, Name: "a"},
+		RawLevelStruct{RawLevel: "2", Level: 2, Name: "b"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+
+	// readonly fields are not written
+	var buf bytes.Buffer
+	w, err := NewWriter(RawLevelStruct{}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal(result); err != nil {
+		t.Fatal(err)
+	}
+	if want := "LEVEL,NAME\n1,a\n2,b\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+	if err := ValidateHeader(RawLevelStruct{}, []string{"NAME", "LEVEL"}); err != nil {
+		t.Errorf("error in ValidateHeader: %s", err)
+	}
+
+	type AmbiguousStruct struct {
+		RawLevel string `csv:"LEVEL"`
+		Level    int    `csv:"LEVEL"`
+	}
+	if _, err := NewSchema(AmbiguousStruct{}); err == nil {
+		t.Error("no error for duplicate csv tag without readonly, but it should")
+	}
+}
+
 func TestCsvHeadersInvalidStructs(t *testing.T) {
 	type InvalidStruct1 struct {
 		Field0 string `csv:"FIELD_0"`
@@ -111,12 +259,12 @@ func TestCsvHeadersInvalidStructs(t *testing.T) {
 	}
 
 	type InvalidStruct2 struct {
-		Field0 string `csv` // empty csv tag
+		Field0 string // empty csv tag
 		Field1 int    `csv:"FIELD_1"`
 	}
 
 	type InvalidStruct3 struct {
-		Field0 string `csv:` // empty csv tag
+		Field0 string `csv:""` // empty csv tag
 		Field1 int    `csv:"FIELD_1"`
 	}
 	noStruct := "string"
@@ -192,7 +340,7 @@ string3;true;3;3.14`, ErrHeaderNotComplete},
 			t.Errorf("no error occured for test '%s', but it should", name)
 		} else {
 			if pe, ok := err.(*csv.ParseError); ok {
-				if pe.Err != test.err {
+				if !errors.Is(pe.Err, test.err) {
 					t.Errorf("wrong error for test '%s': got: %s, wanted %s", name, pe, test.err)
 				}
 			} else {
@@ -220,8 +368,1589 @@ string3;3;true;not.valid`
 				t.Errorf("not enouhg errors produced for wrong types test, got %d, want %d", len(pe), 3)
 			}
 		} else {
-			t.Errorf("wrong error produced for wrong types test: ", err)
+			t.Errorf("wrong error produced for wrong types test: %s", err)
+		}
+	}
+
+}
+
+func TestUnmarshalPointerEndpointStruct(t *testing.T) {
+	data := `FIELD_0;FIELD_1;FIELD_2;FIELD_3
+string1;1;true;1.14`
+	var nilStruct *TestStruct
+	for _, endPointStruct := range []interface{}{TestStruct{}, &TestStruct{}, nilStruct} {
+		m, err := NewMarshaler(endPointStruct, strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("error for endpoint struct %#v: %s", endPointStruct, err)
+		}
+		m.Reader.Comma = ';'
+		result, err := m.Unmarshal()
+		if err != nil {
+			t.Fatalf("error in UnmarshalCSV: %s", err)
+		}
+		if !reflect.DeepEqual(result[0], firstLine) {
+			t.Errorf("wrong value '%v' for first line '%v'", result[0], firstLine)
+		}
+	}
+	ptr := &TestStruct{}
+	for _, invalid := range []interface{}{nil, &ptr, new(string)} {
+		if _, err := NewMarshaler(invalid, strings.NewReader(data)); err != ErrNoStruct {
+			t.Errorf("wrong error for endpoint struct %#v - want: %s, got: %v", invalid, ErrNoStruct, err)
+		}
+	}
+}
+
+func TestUnmarshalPrototypeDefaults(t *testing.T) {
+	data := `FIELD_0;FIELD_1;FIELD_2;FIELD_3
+string1;1;;
+;;false;2.14`
+	prototype := TestStruct{Field0: "default", Field2: true, Field3: 1.0, IngnoredStruct: true}
+	want := []interface{}{
+		TestStruct{Field0: "string1", Field1: 1, Field2: true, Field3: 1.0, IngnoredStruct: true},
+		TestStruct{Field0: "default", Field1: 0, Field2: false, Field3: 2.14, IngnoredStruct: true},
+	}
+	m, err := NewMarshaler(prototype, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.UsePrototypeDefaults = true
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatalf("error in UnmarshalCSV: %s", err)
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+
+	// without prototype defaults empty cells are invalid
+	m, err = NewMarshaler(prototype, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err = m.Unmarshal()
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 2 {
+		t.Errorf("wrong error without prototype defaults - want 2 ParseErrors, got: %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("wrong number of records without prototype defaults - want: 0, got: %d", len(result))
+	}
+}
+
+func TestCsvHeadersSkippedFields(t *testing.T) {
+	type Embedded struct {
+		Field1 int `csv:"FIELD_1"`
+	}
+	type SkippedStruct struct {
+		sync.Mutex
+		Embedded
+		Field0 string `csv:"FIELD_0"`
+		cache  map[string]string
+		hidden int `csv:"HIDDEN"`
+		mu     sync.RWMutex
+	}
+	generatedFieldInfos, err := createFieldInfos(SkippedStruct{})
+	if err != nil {
+		t.Fatalf("error occured in TestCsvHeadersSkippedFields: %s", err)
+	}
+	if len(generatedFieldInfos) != 1 || generatedFieldInfos[0].fieldName != "Field0" {
+		t.Errorf("wrong haeders generated: %v", generatedFieldInfos)
+	}
+
+	data := `FIELD_0;HIDDEN
+string1;1`
+	m, err := NewMarshaler(&SkippedStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatalf("error in UnmarshalCSV: %s", err)
+	}
+	if result[0].(SkippedStruct).Field0 != "string1" || result[0].(SkippedStruct).hidden != 0 {
+		t.Errorf("wrong value for first line: %q", result[0].(SkippedStruct).Field0)
+	}
+}
+
+type payloadOrder struct {
+	ID int
+}
+
+type payloadItem struct {
+	Name string
+}
+
+type PayloadStruct struct {
+	Payload interface{} `csv:"PAYLOAD"`
+	Type    string      `csv:"TYPE"`
+}
+
+func TestUnmarshalFieldDecoder(t *testing.T) {
+	data := `TYPE;PAYLOAD
+ORDER;1
+ITEM;item1
+ORDER;invalid
+UNKNOWN;1`
+	m, err := NewMarshaler(PayloadStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	err = m.RegisterFieldDecoder("PAYLOAD", func(raw string, partial interface{}) (interface{}, error) {
+		switch partial.(PayloadStruct).Type {
+		case "ORDER":
+			id, err := strconv.Atoi(raw)
+			return payloadOrder{ID: id}, err
+		case "ITEM":
+			return payloadItem{Name: raw}, nil
+		}
+		return nil, errors.New("unknown type")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	want := []interface{}{
+		PayloadStruct{Type: "ORDER", Payload: payloadOrder{ID: 1}},
+		PayloadStruct{Type: "ITEM", Payload: payloadItem{Name: "item1"}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 2 || pe[0].Line != 4 || pe[1].Column != 1 {
+		t.Errorf("wrong errors - want 2 ParseErrors for lines 4 and 5, got: %v", err)
+	}
+
+	if err := m.RegisterFieldDecoder("UNKNOWN", nil); err == nil {
+		t.Error("no error for decoder of unknown header, but it should")
+	}
+
+	// without decoder interface{} fields store the cell
+	m, err = NewMarshaler(PayloadStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err = m.Unmarshal()
+	if err != nil || len(result) != 4 || result[0].(PayloadStruct).Payload != "1" {
+		t.Errorf("wrong result without decoder - want: cells as string, got: %v %v", result, err)
+	}
+
+	// other interfaces are not supported
+	type StringerStruct struct {
+		Payload fmt.Stringer `csv:"PAYLOAD"`
+	}
+	m, err = NewMarshaler(StringerStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	_, err = m.Unmarshal()
+	if !errors.Is(err, ErrUnsupportedCSVType) || !strings.Contains(err.Error(), "Payload") {
+		t.Errorf("wrong error without decoder - want: %s for Payload, got: %v", ErrUnsupportedCSVType, err)
+	}
+}
+
+func TestUnmarshalUndecodableField(t *testing.T) {
+	type point struct{ X, Y int }
+	type ChanStruct struct {
+		Events chan int `csv:"EVENTS"`
+		Point  point    `csv:"POINT"`
+	}
+	data := "EVENTS,POINT\n1,2\n3,4\n"
+	m, err := NewMarshaler(ChanStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Unmarshal()
+	if _, ok := err.(ParseErrors); ok || !errors.Is(err, ErrUnsupportedCSVType) || !strings.Contains(err.Error(), "Events of type chan int") {
+		t.Errorf("wrong error - want: single %s for Events, got: %v", ErrUnsupportedCSVType, err)
+	}
+
+	// converters make a type decodable
+	m, err = NewMarshaler(ChanStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterConverter(reflect.TypeOf(make(chan int)), func(string) (chan int, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Unmarshal(); !strings.Contains(fmt.Sprint(err), "Point of type csv.point") {
+		t.Errorf("wrong error - want: %s for Point, got: %v", ErrUnsupportedCSVType, err)
+	}
+}
+
+func TestUnmarshalInferTypes(t *testing.T) {
+	var inferTests = map[string]interface{}{
+		"true":                 true,
+		"FALSE":                false,
+		"True":                 true,
+		"tRue":                 "tRue",
+		"t":                    "t",
+		"1":                    int64(1),
+		"0":                    int64(0),
+		"-42":                  int64(-42),
+		"+7":                   int64(7),
+		"007":                  int64(7),
+		"1.0":                  1.0,
+		"1e3":                  1000.0,
+		".5":                   0.5,
+		"99999999999999999999": 1e20,
+		"1,5":                  "1,5",
+		"NaN":                  "NaN",
+		"Inf":                  "Inf",
+		"0x10":                 "0x10",
+		"1_000":                "1_000",
+		"1.2.3":                "1.2.3",
+		"":                     "",
+		"text":                 "text",
+	}
+	type AnyStruct struct {
+		Value interface{} `csv:"VALUE"`
+	}
+	for cell, want := range inferTests {
+		m, err := NewMarshaler(AnyStruct{}, strings.NewReader("VALUE;X\n"+cell+";\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		m.InferTypes = true
+		result, err := m.Unmarshal()
+		if err != nil || len(result) != 1 {
+			t.Fatalf("error for cell %q: %v", cell, err)
+		}
+		if got := result[0].(AnyStruct).Value; got != want {
+			t.Errorf("wrong value for cell %q - want: %#v, got: %#v", cell, want, got)
+		}
+	}
+}
+
+// testUUID does not implement any interface, like a type from a vendored package.
+type testUUID [4]byte
+
+func parseTestUUID(s string) (testUUID, error) {
+	u := testUUID{}
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(u) {
+		return u, errors.New("invalid uuid")
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+type ConverterStruct struct {
+	ID    testUUID      `csv:"ID"`
+	Delay time.Duration `csv:"DELAY"`
+}
+
+func TestUnmarshalConverter(t *testing.T) {
+	data := `ID;DELAY
+0102aaff;1s
+invalid;1s
+0102aaff;invalid`
+	m, err := NewMarshaler(ConverterStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if err := m.RegisterConverter(reflect.TypeOf(testUUID{}), parseTestUUID); err != nil {
+		t.Fatal(err)
+	}
+	err = m.RegisterConverter(reflect.TypeOf(time.Second), func(s string) (interface{}, error) {
+		return time.ParseDuration(s)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	want := []interface{}{ConverterStruct{ID: testUUID{1, 2, 0xaa, 0xff}, Delay: time.Second}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 2 {
+		t.Errorf("wrong errors - want 2 ParseErrors, got: %v", err)
+	}
+
+	invalidConverters := []interface{}{
+		func(s string) (string, error) { return s, nil },
+		func(s string) testUUID { return testUUID{} },
+		func(i int) (testUUID, error) { return testUUID{}, nil },
+		"no function",
+		nil,
+		(func(string) (interface{}, error))(nil),
+		(func(string) (testUUID, error))(nil),
+	}
+	for _, converter := range invalidConverters {
+		if err := m.RegisterConverter(reflect.TypeOf(testUUID{}), converter); err == nil {
+			t.Errorf("no error for invalid converter %T, but it should", converter)
+		}
+	}
+}
+
+// country validates the iso code on UnmarshalText.
+type country string
+
+func (c *country) UnmarshalText(text []byte) error {
+	if len(text) != 2 || strings.ToUpper(string(text)) != string(text) {
+		return fmt.Errorf("invalid country code: %s", text)
+	}
+	*c = country(text)
+	return nil
+}
+
+// label is a named string without methods.
+type label string
+
+type NamedStringStruct struct {
+	Country country `csv:"COUNTRY"`
+	Label   label   `csv:"LABEL"`
+}
+
+func TestUnmarshalTextUnmarshaler(t *testing.T) {
+	data := `COUNTRY;LABEL
+CH;first
+Switzerland;second
+de;third`
+	m, err := NewMarshaler(NamedStringStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	result, err := m.Unmarshal()
+	want := []interface{}{NamedStringStruct{Country: "CH", Label: "first"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 2 {
+		t.Fatalf("wrong errors - want 2 ParseErrors, got: %v", err)
+	}
+	var fe *FieldError
+	if !errors.As(pe[0].Err, &fe) || fe.Header != "COUNTRY" || pe[0].Line != 3 {
+		t.Errorf("wrong error - want: COUNTRY error in line 3, got: %v", pe[0])
+	}
+}
+
+type (
+	celsius float64
+	level   int8
+	flag    bool
+	ratio   float32
+)
+
+type NamedTypesStruct struct {
+	Temperature celsius `csv:"TEMPERATURE"`
+	Level       level   `csv:"LEVEL"`
+	Flag        flag    `csv:"FLAG"`
+	Ratio       ratio   `csv:"RATIO"`
+	Label       label   `csv:"LABEL"`
+}
+
+func TestUnmarshalNamedTypes(t *testing.T) {
+	data := `TEMPERATURE;LEVEL;FLAG;RATIO;LABEL
+21.5;-3;true;0.25;first
+-4;300;false;1;second`
+	m, err := NewMarshaler(NamedTypesStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	result, err := m.Unmarshal()
+	want := []interface{}{NamedTypesStruct{Temperature: 21.5, Level: -3, Flag: true, Ratio: 0.25, Label: "first"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	// 300 overflows the int8 of level
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 1 || pe[0].Line != 3 || pe[0].Column != 1 {
+		t.Errorf("wrong errors - want: LEVEL error in line 3, got: %v", err)
+	}
+
+	// a converter returning the underlying type cannot be assigned to the named type
+	m, err = NewMarshaler(NamedTypesStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	err = m.RegisterConverter(reflect.TypeOf(celsius(0)), func(s string) (interface{}, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Unmarshal()
+	var fe *FieldError
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 2 || !errors.As(pe[0].Err, &fe) || fe.Field != "Temperature" {
+		t.Errorf("wrong error - want: assignment error of Temperature, got: %v", err)
+	}
+}
+
+type KindsStruct struct {
+	Bool    bool               `csv:"BOOL"`
+	Int     int                `csv:"INT"`
+	Int64   int64              `csv:"INT64"`
+	Float32 float32            `csv:"FLOAT32"`
+	String  string             `csv:"STRING"`
+	Named   celsius            `csv:"NAMED"`
+	Map     map[string]float64 `csv:"MAP_*,glob"`
+	Slice   []int8             `csv:"SLICE_*,glob,ordered"`
+}
+
+func TestUnmarshalAssignmentErrors(t *testing.T) {
+	data := `BOOL;INT;INT64;FLOAT32;STRING;NAMED;MAP_a;SLICE_1
+true;1;2;1.5;s;3.5;1.5;1
+false;3;4;2.5;t;4.5;2.5;2`
+	var assignmentTests = map[string]struct {
+		typ    reflect.Type
+		value  interface{}
+		header string
+	}{
+		"bool":    {reflect.TypeOf(true), "true", "BOOL"},
+		"int":     {reflect.TypeOf(0), int64(1), "INT"},
+		"int64":   {reflect.TypeOf(int64(0)), 1, "INT64"},
+		"float32": {reflect.TypeOf(float32(0)), 1.5, "FLOAT32"},
+		"string":  {reflect.TypeOf(""), []byte("s"), "STRING"},
+		"named":   {reflect.TypeOf(celsius(0)), 3.5, "NAMED"},
+		"map":     {reflect.TypeOf(float64(0)), float32(1.5), "MAP_a"},
+		"slice":   {reflect.TypeOf(int8(0)), nil, "SLICE_1"},
+	}
+	for name, test := range assignmentTests {
+		for _, lazy := range []bool{false, true} {
+			m, err := NewMarshaler(KindsStruct{}, strings.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.Reader.Comma = ';'
+			m.Lazy = lazy
+			value := test.value
+			err = m.RegisterConverter(test.typ, func(string) (interface{}, error) { return value, nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.Unmarshal()
+			if len(result) != 0 {
+				t.Errorf("wrong result for test '%s' - want: no records, got: %v", name, result)
+			}
+			pe, ok := err.(ParseErrors)
+			if !ok || len(pe) != 2 {
+				t.Errorf("wrong errors for test '%s' - want: 2 ParseErrors, got: %v", name, err)
+				continue
+			}
+			var fe *FieldError
+			if !errors.As(pe[0].Err, &fe) || fe.Header != test.header || pe[0].Line != 2 || m.header[pe[0].Column] != test.header {
+				t.Errorf("wrong error for test '%s' - want: %s in line 2, got: %v", name, test.header, pe[0])
+			}
+			if m.Report().ColumnErrors[test.header] != 2 {
+				t.Errorf("wrong column errors for test '%s' - want: %d, got: %v", name, 2, m.Report().ColumnErrors)
+			}
+		}
+	}
+}
+
+func TestUnmarshalErrorRateLimit(t *testing.T) {
+	data := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\n"
+	for i := 0; i < 20; i++ {
+		switch i % 5 {
+		case 1:
+			data += "string;invalid;true;1.14\n"
+		case 3:
+			data += "string;1;true;1.14;too much\n"
+		default:
+			data += "string;1;true;1.14\n"
 		}
 	}
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	m.ErrorRateLimit = 0.3
+	result, err := m.Unmarshal()
+	if _, ok := err.(ParseErrors); !ok {
+		t.Fatalf("error rate of 40%% with limit of 30%% must not fail before minimum rows, got: %v", err)
+	}
+	if len(result) != 12 {
+		t.Errorf("wrong number of records - want: %d, got: %d", 12, len(result))
+	}
+	report := m.Report()
+	if report.Rows != 20 || report.FailedRows != 8 || report.ColumnErrors["FIELD_1"] != 4 {
+		t.Errorf("wrong report: %+v", report)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	m.ErrorRateLimit = 0.3
+	m.ErrorRateMinRows = 10
+	_, err = m.Unmarshal()
+	if !errors.Is(err, ErrErrorRateExceeded) {
+		t.Fatalf("wrong error - want: %s, got: %v", ErrErrorRateExceeded, err)
+	}
+	if re := err.(*ErrorRateError); re.Rate != 0.4 || re.ColumnErrors["FIELD_1"] != 2 {
+		t.Errorf("wrong error rate error: %s", re)
+	}
+}
 
+func TestUnmarshalDedupBy(t *testing.T) {
+	data := `FIELD_0;FIELD_1;FIELD_2;FIELD_3
+string1;1;true;1.14
+string2;1;false;2.14
+string3;2;true;3.14
+string4;1;true;4.14`
+	var dedupTests = map[Keep][]string{
+		KeepFirst: {"string1", "string3"},
+		KeepLast:  {"string4", "string3"},
+	}
+	for keep, want := range dedupTests {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		if err := m.DedupBy("Field1", keep); err != nil {
+			t.Fatal(err)
+		}
+		result, err := m.Unmarshal()
+		if err != nil {
+			t.Fatalf("error in UnmarshalCSV: %s", err)
+		}
+		got := []string{}
+		for _, r := range result {
+			got = append(got, r.(TestStruct).Field0)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong records kept for %d - want: %v, got: %v", keep, want, got)
+		}
+		if m.Report().Duplicates != 2 {
+			t.Errorf("wrong number of duplicates - want: %d, got: %d", 2, m.Report().Duplicates)
+		}
+	}
+
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fieldName := range []string{"Unknown", "IngnoredStruct", "FIELD_1"} {
+		if err := m.DedupBy(fieldName, KeepFirst); err == nil {
+			t.Errorf("no error for DedupBy unknown field %s, but it should", fieldName)
+		}
+	}
+
+	// the field is checked again when the parse starts
+	for name, remove := range map[string]func(m *Marshaler) error{
+		"select": func(m *Marshaler) error { return m.Select("Field0") },
+		"ignore": func(m *Marshaler) error { return m.Ignore("Field1") },
+	} {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		if err := m.DedupBy("Field1", KeepFirst); err != nil {
+			t.Fatal(err)
+		}
+		if err := remove(m); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Unmarshal(); err == nil {
+			t.Errorf("no error for DedupBy field removed by %s, but it should", name)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	var previewTests = []struct {
+		n, rows, errs int
+	}{
+		{0, 0, 0},
+		{2, 1, 1},
+		{2, 1, 1},
+		{10, 2, 1},
+	}
+	for _, test := range previewTests {
+		header, rows, errs, err := m.Preview(test.n)
+		if err != nil {
+			t.Fatalf("error in Preview(%d): %s", test.n, err)
+		}
+		if !reflect.DeepEqual(header, []string{"FIELD_0", "FIELD_1", "FIELD_2", "FIELD_3"}) {
+			t.Errorf("wrong header for Preview(%d): %v", test.n, header)
+		}
+		if len(rows) != test.rows || len(errs) != test.errs {
+			t.Errorf("wrong Preview(%d) - want %d rows and %d errors, got: %v, %v", test.n, test.rows, test.errs, rows, errs)
+		}
+	}
+	result, err := m.Unmarshal()
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 1 {
+		t.Errorf("wrong error after preview - want 1 ParseError, got: %v", err)
+	}
+	if len(result) != 2 || !reflect.DeepEqual(result[0], firstLine) {
+		t.Errorf("wrong result after preview: %v", result)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(notEnoughHeaders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if _, _, _, err := m.Preview(1); err == nil {
+		t.Error("no error for preview with incomplete header, but it should")
+	}
+}
+
+func TestTypeCheck(t *testing.T) {
+	data := `FIELD_0;FIELD_1;FIELD_2;FIELD_3
+string1;1;true;
+string2;2;invalid;2.14
+string3;-3;true;3.14
+string4;x;true;4.14`
+	type CheckedStruct struct {
+		Field0 string  `csv:"FIELD_0"`
+		Field1 int     `csv:"FIELD_1,min=0"`
+		Field2 bool    `csv:"FIELD_2"`
+		Field3 float64 `csv:"FIELD_3,default=1.5"`
+	}
+	m, err := NewMarshaler(CheckedStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	errs, err := m.TypeCheck(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 || errs[0].Line != 3 || !errors.Is(errs[1].Err, ErrOutOfBounds) {
+		t.Errorf("wrong errors - want: errors in lines 3 and 4, got: %v", errs)
+	}
+	// the input is not consumed
+	result, err := m.Unmarshal()
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 3 || len(result) != 1 || m.Report().Rows != 4 {
+		t.Errorf("wrong result after TypeCheck - want: 1 struct and 3 errors, got: %v %v", result, err)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(notEnoughHeaders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if _, err := m.TypeCheck(1); err == nil {
+		t.Error("no error for TypeCheck with incomplete header, but it should")
+	}
+}
+
+func TestUnmarshalOne(t *testing.T) {
+	var unmarshalOneTests = map[string]struct {
+		data string
+		err  error
+	}{
+		"one record":       {"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring1;1;true;1.14", nil},
+		"no record":        {"FIELD_0;FIELD_1;FIELD_2;FIELD_3\n", ErrNoValidRecords},
+		"multiple records": {"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring1;1;true;1.14\nstring2;2;true;2.14", ErrMultipleRecords},
+		"invalid and more": {"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring1;x;true;1.14\nstring2;2;true;2.14", ErrMultipleRecords},
+	}
+	for name, test := range unmarshalOneTests {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(test.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		dest := TestStruct{}
+		err = m.UnmarshalOne(&dest)
+		if err != test.err {
+			t.Errorf("wrong error for test '%s' - want: %v, got: %v", name, test.err, err)
+		}
+		if err == nil && dest != firstLine {
+			t.Errorf("wrong value for test '%s' - want: %v, got: %v", name, firstLine, dest)
+		}
+	}
+
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader("FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring1;x;true;1.14"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if err := m.UnmarshalOne(&TestStruct{}); err == nil {
+		t.Error("no error for invalid record, but it should")
+	}
+	for _, dest := range []interface{}{TestStruct{}, &PayloadStruct{}, nil} {
+		if err := m.UnmarshalOne(dest); err != ErrWrongStructType {
+			t.Errorf("wrong error for dest %#v - want: %s, got: %v", dest, ErrWrongStructType, err)
+		}
+	}
+}
+
+func TestUnmarshalSelect(t *testing.T) {
+	data := `FIELD_3;FIELD_1
+1.14;1
+2.14;invalid`
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if err := m.Select("Field3"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatalf("error in UnmarshalCSV: %s", err)
+	}
+	want := []interface{}{TestStruct{Field3: 1.14}, TestStruct{Field3: 2.14}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	for _, fieldName := range []string{"Unknown", "IngnoredStruct"} {
+		if err := m.Select("Field0", fieldName); err == nil {
+			t.Errorf("no error for Select of unknown field %s, but it should", fieldName)
+		}
+	}
+}
+
+func TestUnmarshalSelectAndIgnore(t *testing.T) {
+	data := "FIELD_0;FIELD_1;FIELD_3\nstring1;corrupt;1.14\n"
+	tt := map[string]func(m *Marshaler) error{
+		"ignore first": func(m *Marshaler) error {
+			if err := m.Ignore("Field1"); err != nil {
+				return err
+			}
+			return m.Select("Field0", "Field1", "Field3")
+		},
+		"select first": func(m *Marshaler) error {
+			if err := m.Select("Field0", "Field1", "Field3"); err != nil {
+				return err
+			}
+			return m.Ignore("Field1")
+		},
+	}
+	for name, configure := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.Reader.Comma = ';'
+			if err := configure(m); err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.Unmarshal()
+			if err != nil {
+				t.Fatalf("error in UnmarshalCSV: %s", err)
+			}
+			want := []interface{}{TestStruct{Field0: "string1", Field3: 1.14}}
+			if !reflect.DeepEqual(result, want) {
+				t.Errorf("wrong result - want: %v, got: %v", want, result)
+			}
+		})
+	}
+}
+
+// wideStruct returns a struct with n int fields tagged FIELD_0 to FIELD_n-1 and a matching csv file with rows records.
+func wideStruct(n, rows int) (interface{}, string) {
+	fields := []reflect.StructField{}
+	header := []string{}
+	record := []string{}
+	for i := 0; i < n; i++ {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(fmt.Sprintf(`csv:"FIELD_%d"`, i)),
+		})
+		header = append(header, fmt.Sprintf("FIELD_%d", i))
+		record = append(record, strconv.Itoa(i))
+	}
+	data := strings.Join(header, ",") + "\n" + strings.Repeat(strings.Join(record, ",")+"\n", rows)
+	return reflect.New(reflect.StructOf(fields)).Elem().Interface(), data
+}
+
+func BenchmarkUnmarshalWideFull(b *testing.B) {
+	s, data := wideStruct(80, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m, err := NewMarshaler(s, strings.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := m.Unmarshal(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalWideSelect(b *testing.B) {
+	s, data := wideStruct(80, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m, err := NewMarshaler(s, strings.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := m.Select("Field0", "Field10", "Field20", "Field30", "Field40"); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := m.Unmarshal(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestHeadersAndColumns(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader("FIELD_3,FIELD_1,FIELD_2,FIELD_0,FIELD_1\n1.5,1,true,a,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Headers() != nil || m.Columns() != nil {
+		t.Errorf("header before Unmarshal - want: nil, got: %v %v", m.Headers(), m.Columns())
+	}
+	m.Reader.FieldsPerRecord = 5
+	if _, err := m.Unmarshal(); err != nil {
+		t.Fatal(err)
+	}
+	wantHeaders := []string{"FIELD_3", "FIELD_1", "FIELD_2", "FIELD_0", "FIELD_1"}
+	if !reflect.DeepEqual(m.Headers(), wantHeaders) {
+		t.Errorf("wrong headers - want: %v, got: %v", wantHeaders, m.Headers())
+	}
+	// duplicate names resolve to the first column
+	wantColumns := map[string]int{"FIELD_3": 0, "FIELD_1": 1, "FIELD_2": 2, "FIELD_0": 3}
+	if !reflect.DeepEqual(m.Columns(), wantColumns) {
+		t.Errorf("wrong columns - want: %v, got: %v", wantColumns, m.Columns())
+	}
+}
+
+func BenchmarkResolveHeader(b *testing.B) {
+	s, data := wideStruct(1000, 0)
+	header := strings.Split(strings.TrimSpace(data), ",")
+	m, err := NewMarshaler(s, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := m.resolveHeader(header); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func narrowData(rows int) string {
+	return "FIELD_0,FIELD_1,FIELD_2,FIELD_3\n" + strings.Repeat("string,1,true,1.14\n", rows)
+}
+
+func BenchmarkUnmarshalNarrow(b *testing.B) {
+	data := narrowData(1000)
+	b.Run("interface", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := m.Unmarshal(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("slice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+			if err != nil {
+				b.Fatal(err)
+			}
+			structs := []TestStruct{}
+			if err := m.UnmarshalSlice(&structs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkUnmarshalWide(b *testing.B) {
+	s, data := wideStruct(80, 1000)
+	b.Run("interface", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m, err := NewMarshaler(s, strings.NewReader(data))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := m.Unmarshal(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("slice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m, err := NewMarshaler(s, strings.NewReader(data))
+			if err != nil {
+				b.Fatal(err)
+			}
+			structs := reflect.New(reflect.SliceOf(reflect.TypeOf(s))).Interface()
+			if err := m.UnmarshalSlice(structs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestUnmarshalSlice(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	structs := []TestStruct{{Field0: "existing"}}
+	err = m.UnmarshalSlice(&structs)
+	if errs, ok := err.(ParseErrors); !ok || len(errs) != 1 || errs[0].Line != 3 {
+		t.Errorf("wrong error - want: one error in line 3, got: %v", err)
+	}
+	want := []TestStruct{
+		{Field0: "string1", Field1: 1, Field2: true, Field3: 1.14},
+		{Field0: "string3", Field1: 3, Field2: true, Field3: 3.14},
+	}
+	if !reflect.DeepEqual(structs, want) {
+		t.Errorf("wrong structs - want: %v, got: %v", want, structs)
+	}
+
+	data := `FIELD_0;FIELD_1;FIELD_2;FIELD_3
+string1;1;true;1.14
+string2;1;false;2.14
+string3;2;true;3.14
+string4;1;true;4.14`
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if err := m.DedupBy("Field1", KeepLast); err != nil {
+		t.Fatal(err)
+	}
+	structs = nil
+	if err := m.UnmarshalSlice(&structs); err != nil {
+		t.Fatal(err)
+	}
+	if len(structs) != 2 || structs[0].Field0 != "string4" || structs[1].Field0 != "string3" {
+		t.Errorf("wrong records kept - want: [string4 string3], got: %v", structs)
+	}
+
+	for _, dest := range []interface{}{structs, &[]interface{}{}, &[]*TestStruct{}, nil} {
+		if err := m.UnmarshalSlice(dest); err != ErrWrongStructType {
+			t.Errorf("wrong error for %T - want: %s, got: %v", dest, ErrWrongStructType, err)
+		}
+	}
+}
+
+func TestUnmarshalInto(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	structs := []TestStruct{{Field0: "existing"}}
+	n, err := m.UnmarshalInto(&structs)
+	if errs, ok := err.(ParseErrors); !ok || len(errs) != 1 || errs[0].Line != 3 {
+		t.Errorf("wrong error - want: one error in line 3, got: %v", err)
+	}
+	want := []TestStruct{
+		{Field0: "existing"},
+		{Field0: "string1", Field1: 1, Field2: true, Field3: 1.14},
+		{Field0: "string3", Field1: 3, Field2: true, Field3: 3.14},
+	}
+	if n != 2 || !reflect.DeepEqual(structs, want) {
+		t.Errorf("wrong structs - want: 2 appended to %v, got: %d %v", want, n, structs)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	pointers := []*TestStruct{}
+	if n, _ := m.UnmarshalInto(&pointers); n != 2 || len(pointers) != 2 || *pointers[1] != want[2] {
+		t.Errorf("wrong pointers - want: %v, got: %d %v", want[1:], n, pointers)
+	}
+
+	// nothing is appended after a fatal error
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader("FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,true,1.5,too much\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	structs = []TestStruct{{Field0: "existing"}}
+	if n, err := m.UnmarshalInto(&structs); err == nil || n != 0 || len(structs) != 1 {
+		t.Errorf("wrong result - want: error and no appended structs, got: %d %v %v", n, structs, err)
+	}
+
+	for _, dest := range []interface{}{structs, &[]interface{}{}, &[]**TestStruct{}, nil} {
+		if _, err := m.UnmarshalInto(dest); err != ErrWrongStructType {
+			t.Errorf("wrong error for %T - want: %s, got: %v", dest, ErrWrongStructType, err)
+		}
+	}
+}
+
+func TestUnmarshalReturnPartialOnError(t *testing.T) {
+	data := `FIELD_0;FIELD_1;FIELD_2;FIELD_3
+string1;1;true;1.14
+string2;2;true;2.14
+string3;3;true;3.14;too much
+string4;4;true;4.14`
+	for _, partial := range []bool{false, true} {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		m.ReturnPartialOnError = partial
+		result, err := m.Unmarshal()
+		if _, ok := err.(*csv.ParseError); !ok {
+			t.Fatalf("partial %t: wrong error - want: *csv.ParseError, got: %v", partial, err)
+		}
+		want := 0
+		if partial {
+			want = 2
+		}
+		if len(result) != want {
+			t.Errorf("partial %t: wrong number of structs - want: %d, got: %d", partial, want, len(result))
+		}
+
+		m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		m.ReturnPartialOnError = partial
+		structs := []TestStruct{}
+		if err := m.UnmarshalSlice(&structs); err == nil {
+			t.Fatalf("partial %t: expected error", partial)
+		}
+		if len(structs) != want {
+			t.Errorf("partial %t: wrong number of structs in slice - want: %d, got: %d", partial, want, len(structs))
+		}
+	}
+}
+
+func TestUnmarshalPostDecode(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	rejected := errors.New("rejected")
+	lines := []int{}
+	m.PostDecode = func(ptr interface{}, line int) error {
+		lines = append(lines, line)
+		s := ptr.(*TestStruct)
+		if s.Field1 == 3 {
+			return rejected
+		}
+		s.Field0 = strings.ToUpper(s.Field0)
+		return nil
+	}
+	result, err := m.Unmarshal()
+	want := []interface{}{TestStruct{Field0: "STRING1", Field1: 1, Field2: true, Field3: 1.14}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	// line 3 fails to decode and is not passed to PostDecode
+	if !reflect.DeepEqual(lines, []int{2, 4}) {
+		t.Errorf("wrong lines passed to PostDecode - want: %v, got: %v", []int{2, 4}, lines)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 2 || pe[1].Line != 4 || pe[1].Column != -1 || !errors.Is(pe[1].Err, rejected) {
+		t.Errorf("wrong errors - want: %s in line 4, got: %v", rejected, err)
+	}
+	if m.Report().FailedRows != 2 {
+		t.Errorf("wrong number of failed rows - want: %d, got: %d", 2, m.Report().FailedRows)
+	}
+}
+
+func TestUnmarshalIgnoreAndMapHeader(t *testing.T) {
+	data := `VENDOR_0;FIELD_1;FIELD_2;PRICE
+string1;corrupt;true;1.14`
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if err := m.Ignore("Field1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MapHeader(map[string]string{"VENDOR_0": "FIELD_0", "PRICE": "FIELD_3"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatalf("error in UnmarshalCSV: %s", err)
+	}
+	want := TestStruct{Field0: "string1", Field2: true, Field3: 1.14}
+	if len(result) != 1 || !reflect.DeepEqual(result[0], want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	if err := m.Ignore("Unknown"); err == nil {
+		t.Error("no error for Ignore of unknown field, but it should")
+	}
+	if err := m.MapHeader(map[string]string{"OLD": "UNKNOWN"}); err == nil {
+		t.Error("no error for MapHeader to unknown header, but it should")
+	}
+}
+
+func TestUnmarshalRequireHeaderOrder(t *testing.T) {
+	var headerOrderTests = map[string]struct {
+		data  string
+		order []string
+		err   bool
+	}{
+		"struct order":           {"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring1;1;true;1.14", nil, false},
+		"additional columns":     {"FIELD_0;OTHER;FIELD_1;FIELD_2;FIELD_3\nstring1;x;1;true;1.14", nil, false},
+		"wrong order":            {"FIELD_0;FIELD_2;FIELD_1;FIELD_3\nstring1;true;1;1.14", nil, true},
+		"explicit order":         {"FIELD_3;FIELD_2;FIELD_1;FIELD_0\n1.14;true;1;string1", []string{"FIELD_3", "FIELD_2", "FIELD_1", "FIELD_0"}, false},
+		"explicit wrong order":   {"FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring1;1;true;1.14", []string{"FIELD_3", "FIELD_2", "FIELD_1", "FIELD_0"}, true},
+		"explicit partial order": {"FIELD_1;FIELD_0;FIELD_2;FIELD_3\n1;string1;true;1.14", []string{"FIELD_2", "FIELD_3"}, false},
+	}
+	for name, test := range headerOrderTests {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(test.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		m.RequireHeaderOrder = true
+		m.HeaderOrder = test.order
+		_, err = m.Unmarshal()
+		if test.err != errors.Is(err, ErrHeaderOrder) {
+			t.Errorf("wrong error for test '%s' - want error: %t, got: %v", name, test.err, err)
+		}
+	}
+}
+
+func TestUnmarshalVariableFieldsPerRecord(t *testing.T) {
+	var variableTests = map[string]struct {
+		data string
+		want []TestStruct
+	}{
+		"not enough fields": {notEnoughFields, []TestStruct{
+			{Field0: "string1", Field1: 1, Field2: true},
+			{Field0: "string2", Field1: 2, Field2: true, Field3: 1.14},
+			{Field0: "string3", Field1: 3, Field2: true},
+		}},
+		"too many fields": {tooManyFields, []TestStruct{
+			{Field0: "string1", Field1: 1, Field2: true, Field3: 1.14},
+			{Field0: "string2", Field1: 2, Field2: false, Field3: 2.14},
+			{Field0: "string3", Field1: 3, Field2: true, Field3: 3.14},
+		}},
+	}
+	for name, test := range variableTests {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(test.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		m.Reader.FieldsPerRecord = -1
+		// missing cells are empty and keep the zero value of the prototype
+		m.UsePrototypeDefaults = true
+		structs, err := m.Unmarshal()
+		if err != nil {
+			t.Fatalf("error in test '%s': %s", name, err)
+		}
+		if len(structs) != len(test.want) {
+			t.Fatalf("wrong number of structs for test '%s' - want: %d, got: %d", name, len(test.want), len(structs))
+		}
+		for i, s := range structs {
+			if s.(TestStruct) != test.want[i] {
+				t.Errorf("wrong struct %d for test '%s' - want: %v, got: %v", i, name, test.want[i], s)
+			}
+		}
+	}
+}
+
+func TestUnmarshalIgnoreExtraColumns(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(tooManyFields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.IgnoreExtraColumns = true
+	structs, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		TestStruct{Field0: "string1", Field1: 1, Field2: true, Field3: 1.14},
+		TestStruct{Field0: "string2", Field1: 2, Field2: false, Field3: 2.14},
+		TestStruct{Field0: "string3", Field1: 3, Field2: true, Field3: 3.14},
+	}
+	if !reflect.DeepEqual(structs, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, structs)
+	}
+	report := m.Report()
+	if report.ExtraColumnRows != 2 || report.FailedRows != 0 || len(report.Warnings) != 2 {
+		t.Fatalf("wrong report - want: 2 rows with extra columns, got: %+v", report)
+	}
+	if w := report.Warnings[1]; w.Line != 4 || w.Column != 4 || !errors.Is(w.Err, ErrExtraColumns) {
+		t.Errorf("wrong warning - want: %s in line 4, column 4, got: %v", ErrExtraColumns, w)
+	}
+
+	// rows with less columns than the header still fail
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(notEnoughFields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.IgnoreExtraColumns = true
+	if _, err := m.Unmarshal(); !errors.Is(err, csv.ErrFieldCount) {
+		t.Errorf("wrong error - want: %s, got: %v", csv.ErrFieldCount, err)
+	}
+}
+
+func TestUnmarshalMissingCells(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{Field3: 9.5}, strings.NewReader(notEnoughFields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Reader.FieldsPerRecord = -1
+	m.UsePrototypeDefaults = true
+	structs, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(structs) != 3 || structs[0].(TestStruct).Field3 != 9.5 {
+		t.Errorf("wrong prototype default for missing cell - want: %v, got: %v", 9.5, structs)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(notEnoughFields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Reader.FieldsPerRecord = -1
+	m.ErrorOnMissingCells = true
+	structs, err = m.Unmarshal()
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 2 || !errors.Is(errs[0].Err, ErrMissingCell) {
+		t.Fatalf("wrong error - want: 2 errors with %s, got: %v", ErrMissingCell, err)
+	}
+	if errs[0].Line != 2 || errs[1].Line != 4 || errs[0].Column != 3 {
+		t.Errorf("wrong error positions - want: lines 2 and 4, column 3, got: %v", errs)
+	}
+	if len(structs) != 1 {
+		t.Errorf("wrong number of structs - want: %d, got: %d", 1, len(structs))
+	}
+}
+
+func TestCaptureRawOnError(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\n\"a, \"\"quoted\"\"\nline\",x,true,1.5\r\nstring,1,true,1.5\nstring,1,true\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	m.CaptureRawOnError = true
+	_, err = m.Unmarshal()
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("wrong errors - want: 2 ParseErrors, got: %v", err)
+	}
+	want := []string{"\"a, \"\"quoted\"\"\nline\",x,true,1.5", "string,1,true"}
+	for i, err := range errs {
+		var fe *FieldError
+		if !errors.As(err.Err, &fe) {
+			t.Fatalf("error %d is no FieldError: %v", i, err.Err)
+		}
+		if fe.Raw != want[i] {
+			t.Errorf("wrong raw line %d - want: %q, got: %q", i, want[i], fe.Raw)
+		}
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	m.CaptureRawOnError = true
+	m.MaxRawSize = 4
+	_, _, errs, err = m.Preview(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Err.(*FieldError).Raw != "\"a, " {
+		t.Errorf("wrong raw line of Preview - want: %q, got: %v", "\"a, ", errs)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	if errors.As(err, &errs) && errs[0].Err.(*FieldError).Raw != "" {
+		t.Errorf("raw line without CaptureRawOnError: %q", errs[0].Err.(*FieldError).Raw)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tt := map[string]struct {
+		data    string
+		opts    []Option
+		missing []string
+		extra   []string
+	}{
+		"match":             {data: "FIELD_3,FIELD_2,FIELD_1,FIELD_0\n"},
+		"missing":           {data: "FIELD_0,FIELD_1\n", missing: []string{"FIELD_2", "FIELD_3"}},
+		"extra":             {data: "FIELD_0,FIELD_1,FIELD_2,FIELD_3,FIELD_4\n", extra: []string{"FIELD_4"}},
+		"missing and extra": {data: "FIELD_0,FIELD_1,FIELD_2,F3\n", missing: []string{"FIELD_3"}, extra: []string{"F3"}},
+		"alias": {
+			data: "FIELD_0,FIELD_1,FIELD_2,F3\n",
+			opts: []Option{WithHeaderAliases(map[string]string{"F3": "FIELD_3"})},
+		},
+	}
+	for name, tc := range tt {
+		missing, extra, err := Diff(TestStruct{}, strings.NewReader(tc.data), tc.opts...)
+		if err != nil {
+			t.Errorf("%s: error in Diff: %s", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(missing, tc.missing) || !reflect.DeepEqual(extra, tc.extra) {
+			t.Errorf("%s: wrong diff - want: %v and %v, got: %v and %v", name, tc.missing, tc.extra, missing, extra)
+		}
+	}
+	if _, _, err := Diff(TestStruct{}, strings.NewReader("")); err != io.EOF {
+		t.Errorf("wrong error for empty input - want: %s, got: %v", io.EOF, err)
+	}
+}
+
+func TestUnmarshalGrouped(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,false,2.5\nc,x,true,1.5\nd,4,true,1.50\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := m.UnmarshalGrouped("Field3")
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("wrong errors - want: 1 ParseError, got: %v", err)
+	}
+	want := map[string][]interface{}{
+		"1.5": {TestStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5}, TestStruct{Field0: "d", Field1: 4, Field2: true, Field3: 1.5}},
+		"2.5": {TestStruct{Field0: "b", Field1: 2, Field2: false, Field3: 2.5}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("wrong groups - want: %v, got: %v", want, groups)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.UnmarshalGrouped("Field4"); err == nil {
+		t.Error("no error for unknown field, but it should")
+	}
+}
+
+func TestHeaderLine(t *testing.T) {
+	data := "# export of 2020-01-01\n# source: test\nFIELD_0,FIELD_1,FIELD_2,FIELD_3\n\"multi\nline\",1,true,1.5\n# comment\nstring,x,true,1.5\n\"broken,2,true,1.5\nstring,3,true,1.5\nstring,4,yes,1.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithComment('#'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	if m.HeaderLine() != 0 {
+		t.Errorf("wrong header line before Unmarshal - want: 0, got: %d", m.HeaderLine())
+	}
+	_, err = m.Unmarshal()
+	if m.HeaderLine() != 3 {
+		t.Errorf("wrong header line - want: 3, got: %d", m.HeaderLine())
+	}
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("wrong error - want: ParseErrors, got: %v", err)
+	}
+	lines := []int{}
+	for _, err := range errs {
+		lines = append(lines, err.Line)
+	}
+	// the unterminated quote of line 8 is detected at the end of the input
+	if want := []int{7, 10, 10}; !reflect.DeepEqual(lines, want) || errs[1].StartLine != 8 {
+		t.Errorf("wrong error lines - want: %v and start line 8, got: %v: %s", want, lines, errs)
+	}
+}
+
+// TestFieldsWithoutColumn pins which fields require a column, with and without RequireUntagged:
+// named fields always do, ignored fields never and fields of tags without name only with
+// RequireUntagged. Fields without csv tag are rejected.
+func TestFieldsWithoutColumn(t *testing.T) {
+	type Untagged struct {
+		Field0 string `csv:"FIELD_0"`
+		Field1 string
+	}
+	type Fallback struct {
+		Field0 string `csv:"FIELD_0"`
+		Field1 string `csv:",upper"`
+	}
+	ignored := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\n"
+	ignoredColumn := "FIELD_0,FIELD_1,FIELD_2,FIELD_3,IngnoredStruct\na,1,true,1.5,true\n"
+	tt := map[string]struct {
+		s           interface{}
+		data        string
+		require     bool
+		schemaError bool
+		headerError bool
+		want        interface{}
+	}{
+		"ignored without column":                  {s: TestStruct{}, data: ignored, want: TestStruct{"a", 1, true, 1.5, false}},
+		"ignored without column require":          {s: TestStruct{}, data: ignored, require: true, want: TestStruct{"a", 1, true, 1.5, false}},
+		"ignored with column":                     {s: TestStruct{}, data: ignoredColumn, want: TestStruct{"a", 1, true, 1.5, false}},
+		"ignored with column require":             {s: TestStruct{}, data: ignoredColumn, require: true, want: TestStruct{"a", 1, true, 1.5, false}},
+		"named without column":                    {s: TestStruct{}, data: "FIELD_0,FIELD_1,FIELD_2\na,1,true\n", headerError: true},
+		"named without column require":            {s: TestStruct{}, data: "FIELD_0,FIELD_1,FIELD_2\na,1,true\n", require: true, headerError: true},
+		"untagged":                                {s: Untagged{}, schemaError: true},
+		"untagged require":                        {s: Untagged{}, require: true, schemaError: true},
+		"tag without name with column":            {s: Fallback{}, data: "FIELD_0,Field1\na,b\n", want: Fallback{"a", "B"}},
+		"tag without name with column require":    {s: Fallback{}, data: "FIELD_0,Field1\na,b\n", require: true, want: Fallback{"a", "B"}},
+		"tag without name without column":         {s: Fallback{}, data: "FIELD_0\na\n", want: Fallback{"a", ""}},
+		"tag without name without column require": {s: Fallback{}, data: "FIELD_0\na\n", require: true, headerError: true},
+	}
+	for name, tc := range tt {
+		m, err := NewMarshaler(tc.s, strings.NewReader(tc.data))
+		if (err != nil) != tc.schemaError {
+			t.Errorf("%s: wrong error of NewMarshaler: %v", name, err)
+		}
+		if err != nil {
+			continue
+		}
+		m.RequireUntagged = tc.require
+		result, err := m.Unmarshal()
+		if errors.Is(err, ErrHeaderNotComplete) != tc.headerError {
+			t.Errorf("%s: wrong error of Unmarshal: %v", name, err)
+		}
+		if err == nil && !reflect.DeepEqual(result, []interface{}{tc.want}) {
+			t.Errorf("%s: wrong result - want: %v, got: %v", name, tc.want, result)
+		}
+	}
+
+	// header validation follows the same rules
+	if err := ValidateHeader(Fallback{}, []string{"FIELD_0"}); err != nil {
+		t.Errorf("wrong error of ValidateHeader: %v", err)
+	}
+	m, err := NewMarshaler(Fallback{}, strings.NewReader("FIELD_0\na\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.RequireUntagged = true
+	var herr *HeaderError
+	if err := m.ValidateHeaderFromReader(); !errors.As(err, &herr) || !reflect.DeepEqual(herr.Missing, []string{"Field1"}) {
+		t.Errorf("wrong error of ValidateHeaderFromReader - want: missing Field1, got: %v", err)
+	}
+}
+
+func TestColumnCount(t *testing.T) {
+	data := "# export\nFIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,true\nc,x,true,3.5\nd,4,true,4.5,extra\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithComment('#'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("wrong errors - want: 3 ParseErrors, got: %v", err)
+	}
+	if m.Reader.FieldsPerRecord != 4 {
+		t.Errorf("wrong FieldsPerRecord - want: 4, got: %d", m.Reader.FieldsPerRecord)
+	}
+	want := map[int]ColumnCountError{
+		4: {Expected: 4, Actual: 3, HeaderLine: 2},
+		6: {Expected: 4, Actual: 5, HeaderLine: 2},
+	}
+	for _, e := range errs {
+		var ce *ColumnCountError
+		if !errors.As(e.Err, &ce) {
+			continue
+		}
+		if !errors.Is(e.Err, csv.ErrFieldCount) {
+			t.Errorf("wrong error - want: %s, got: %v", csv.ErrFieldCount, e.Err)
+		}
+		if *ce != want[e.Line] {
+			t.Errorf("wrong error on line %d - want: %+v, got: %+v", e.Line, want[e.Line], *ce)
+		}
+		delete(want, e.Line)
+	}
+	if len(want) > 0 {
+		t.Errorf("missing column count errors: %v", want)
+	}
+	report := m.Report()
+	if report.ColumnCountRows != 2 || report.FailedRows != 3 {
+		t.Errorf("wrong report - want: 2 column count rows of 3 failed, got: %d of %d", report.ColumnCountRows, report.FailedRows)
+	}
+
+	// explicit FieldsPerRecord is kept
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader("FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.FieldsPerRecord = -1
+	if _, err := m.Unmarshal(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Reader.FieldsPerRecord != -1 {
+		t.Errorf("wrong FieldsPerRecord - want: -1, got: %d", m.Reader.FieldsPerRecord)
+	}
+}
+
+func TestUnmarshalBrokenHeaderLazy(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader("\"FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) || !errors.Is(pe.Err, csv.ErrQuote) {
+		t.Errorf("wrong error - want: %s, got: %v", csv.ErrQuote, err)
+	}
+}
+
+func TestUnmarshalTwice(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader("FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,x,true,1.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	var first ParseErrors
+	if !errors.As(err, &first) || len(first) != 1 {
+		t.Fatalf("wrong errors of first parse - want: 1 ParseError, got: %v", err)
+	}
+	want := append(ParseErrors{}, first...)
+
+	m.Reader = csv.NewReader(strings.NewReader("FIELD_3,FIELD_2,FIELD_1,FIELD_0\n1.5,true,y,b\n2.5,true,z,c\n"))
+	_, err = m.Unmarshal()
+	var second ParseErrors
+	if !errors.As(err, &second) || len(second) != 2 {
+		t.Fatalf("wrong errors of second parse - want: 2 ParseErrors, got: %v", err)
+	}
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("errors of first parse changed - want: %v, got: %v", want, first)
+	}
+	if second[0].Column != 2 {
+		t.Errorf("wrong column of second parse - want: 2, got: %d", second[0].Column)
+	}
+
+	// positions of the previous file are not reused
+	m.Reader = csv.NewReader(strings.NewReader("FIELD_0,FIELD_2,FIELD_3\na,true,1.5\n"))
+	if _, err := m.Unmarshal(); !errors.Is(err, ErrHeaderNotComplete) {
+		t.Errorf("wrong error for incomplete header - want: %s, got: %v", ErrHeaderNotComplete, err)
+	}
 }
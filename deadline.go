@@ -0,0 +1,51 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// deadlineCheckRows is the number of records read between two checks of WithDeadline.
+const deadlineCheckRows = 256
+
+var (
+	ErrDeadlineExceeded = errors.New("deadline exceeded")
+)
+
+// DeadlineError reports a parse stopped by WithDeadline. It unwraps to ErrDeadlineExceeded.
+type DeadlineError struct {
+	Deadline time.Duration // deadline of WithDeadline
+	Rows     int           // number of data rows read
+	Offset   int64         // input offset after the last read record, see InputOffset
+}
+
+// Error returns the DeadlineError as string
+func (e *DeadlineError) Error() string {
+	return fmt.Sprintf("%s after %s: rows:%d,offset:%d", ErrDeadlineExceeded, e.Deadline, e.Rows, e.Offset)
+}
+
+// Unwrap returns ErrDeadlineExceeded
+func (e *DeadlineError) Unwrap() error {
+	return ErrDeadlineExceeded
+}
+
+// WithDeadline stops a parse that takes longer than d with a DeadlineError, e.g. for previews
+// that have to answer in time. Unmarshal, UnmarshalSlice and UnmarshalInto return the records
+// decoded so far along with it, the Offset of the error allows to continue after them. The
+// elapsed time is only checked every 256 records, so a parse may take a bit longer than d.
+func WithDeadline(d time.Duration) Option {
+	return func(m *Marshaler) {
+		m.deadline = d
+	}
+}
+
+// checkDeadline returns a DeadlineError if the parse started at m.start exceeded the deadline of
+// WithDeadline, it checks the time only every deadlineCheckRows records.
+func (m *Marshaler) checkDeadline(r *csv.Reader) error {
+	if m.deadline <= 0 || m.line == 0 || m.line%deadlineCheckRows != 0 || time.Since(m.start) <= m.deadline {
+		return nil
+	}
+	return &DeadlineError{Deadline: m.deadline, Rows: m.report.Rows, Offset: m.inputOffset(r)}
+}
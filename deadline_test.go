@@ -0,0 +1,89 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDeadline(t *testing.T) {
+	header := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\n"
+	b := &strings.Builder{}
+	b.WriteString(header)
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(b, "a,%d,true,1.5\n", i)
+	}
+	data := b.String()
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithDeadline(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.PostDecode = func(ptr interface{}, line int) error {
+		if line == 2 {
+			time.Sleep(2 * time.Millisecond)
+		}
+		return nil
+	}
+	result, err := m.Unmarshal()
+	var de *DeadlineError
+	if !errors.Is(err, ErrDeadlineExceeded) || !errors.As(err, &de) {
+		t.Fatalf("wrong error - want: %s, got: %v", ErrDeadlineExceeded, err)
+	}
+	// checked before the 257th record, after the header and 255 data rows
+	if len(result) != 255 || de.Rows != 255 || de.Offset != m.InputOffset() {
+		t.Fatalf("wrong partial result - want: 255 rows at offset %d, got: %d of %d rows at offset %d", m.InputOffset(), len(result), de.Rows, de.Offset)
+	}
+
+	// the rest of the file continues at the offset
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(header+data[de.Offset:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 745 || rest[0].(TestStruct).Field1 != 255 {
+		t.Errorf("wrong rest - want: 745 rows starting with 255, got: %d rows starting with %v", len(rest), rest[0])
+	}
+
+	// Next stops at the deadline as well
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data), WithDeadline(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for m.Next() {
+		n++
+	}
+	if !errors.Is(m.Err(), ErrDeadlineExceeded) || n != 255 {
+		t.Errorf("wrong stream - want: %s after 255 records, got: %v after %d", ErrDeadlineExceeded, m.Err(), n)
+	}
+}
+
+// BenchmarkWithDeadline shows that checking the deadline has no measurable overhead.
+func BenchmarkWithDeadline(b *testing.B) {
+	s := &strings.Builder{}
+	s.WriteString("FIELD_0,FIELD_1,FIELD_2,FIELD_3\n")
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(s, "a,%d,true,1.5\n", i)
+	}
+	data := s.String()
+	for name, deadline := range map[string]time.Duration{"none": 0, "deadline": time.Hour} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithDeadline(deadline))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := m.Unmarshal(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package csv
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// resolveDelimiter applies the delimiter once, before the first line is read: it sets a single
+// rune DelimiterString as Reader.Comma and the splitter of the lines of m.Reader. Later changes of
+// DelimiterString and Reader.Comma have no effect on the splitter.
+func (m *Marshaler) resolveDelimiter() {
+	if m.delimiterResolved {
+		return
+	}
+	m.delimiterResolved = true
+	m.lines.split = m.splitter()
+}
+
+// splitter returns the function that translates lines with cells separated by DelimiterString,
+// the lines of a tab-separated or a fixed-width file for encoding/csv, nil for other files. A
+// DelimiterString of a single rune is set as Reader.Comma instead.
+func (m *Marshaler) splitter() func(line []byte) []byte {
+	if m.fixedWidth {
+		return m.fixedWidthSplitter()
+	}
+	if m.tsv { // escapes are decoded per cell, see BackslashEscapes
+		return m.literalSplitter([]byte("\t"))
+	}
+	switch utf8.RuneCountInString(m.DelimiterString) {
+	case 0:
+		return nil
+	case 1:
+		m.Reader.Comma, _ = utf8.DecodeRuneInString(m.DelimiterString)
+		return nil
+	}
+	return m.literalSplitter([]byte(m.DelimiterString))
+}
+
+// literalSplitter returns the function that translates lines with literal cells separated by
+// delimiter to lines of Reader.Comma separated cells, quoted where needed.
+func (m *Marshaler) literalSplitter(delimiter []byte) func(line []byte) []byte {
+	comma, comment := string(m.Reader.Comma), m.Reader.Comment
+	return func(line []byte) []byte {
+		if comment != 0 && bytes.HasPrefix(line, []byte(string(comment))) {
+			return line
+		}
+		body := bytes.TrimSuffix(line, []byte("\n"))
+		body = bytes.TrimSuffix(body, []byte("\r"))
+		translated := make([]byte, 0, len(line)+8)
+		for i, cell := range bytes.Split(body, delimiter) {
+			if i > 0 {
+				translated = append(translated, comma...)
+			}
+			translated = appendCell(translated, cell, comma)
+		}
+		return append(translated, line[len(body):]...)
+	}
+}
+
+// appendCell appends cell to line, quoted if encoding/csv would not read it literally.
+func appendCell(line, cell []byte, comma string) []byte {
+	if !bytes.Contains(cell, []byte(comma)) && !bytes.ContainsAny(cell, "\"\r") {
+		return append(line, cell...)
+	}
+	line = append(line, '"')
+	line = append(line, bytes.ReplaceAll(cell, []byte(`"`), []byte(`""`))...)
+	return append(line, '"')
+}
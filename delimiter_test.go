@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDelimiterString(t *testing.T) {
+	data := "FIELD_0||FIELD_1||FIELD_2||FIELD_3\n\"a, b\"||1||true||1.5\r\n# comment||x\nc||x||true||2.5\nd||3||false\ne||4||true||4.5\n"
+	metrics := &countingMetrics{}
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithDelimiter("||"), WithComment('#'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	m.Metrics = metrics
+
+	_, rows, _, err := m.Preview(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{TestStruct{Field0: `"a, b"`, Field1: 1, Field2: true, Field3: 1.5}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("wrong preview - want: %v, got: %v", want, rows)
+	}
+
+	result, err := m.Unmarshal()
+	want := []interface{}{
+		TestStruct{Field0: `"a, b"`, Field1: 1, Field2: true, Field3: 1.5},
+		TestStruct{Field0: "e", Field1: 4, Field2: true, Field3: 4.5},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("wrong errors - want: 2 ParseErrors, got: %v", err)
+	}
+	if errs[0].Column != 1 || errs[1].Line != 5 {
+		t.Errorf("wrong errors - want: column 1 and line 5, got: %v", errs)
+	}
+	if report := m.Report(); report.Rows != 4 || report.FailedRows != 2 {
+		t.Errorf("wrong report - want: 4 rows and 2 failed, got: %d and %d", report.Rows, report.FailedRows)
+	}
+	if metrics.bytes != int64(len(data)) {
+		t.Errorf("wrong number of bytes - want: %d, got: %d", len(data), metrics.bytes)
+	}
+}
+
+func TestDelimiterStringSingleRune(t *testing.T) {
+	data := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na, b;1;true;1.5\n"
+	want := []interface{}{TestStruct{Field0: "a, b", Field1: 1, Field2: true, Field3: 1.5}}
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithDelimiter(";"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+}
@@ -0,0 +1,123 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var (
+	ErrUnknownRecordType = errors.New("unknown record type")
+)
+
+// Dispatcher decodes csv files with interleaved record types, e.g. orders followed by their
+// items, into one endpoint struct per type. The type of a row is the cell of the discriminator
+// column, all types are resolved against the shared header of the file.
+type Dispatcher struct {
+	Reader     *csv.Reader
+	Lazy       bool // if true, rows with an unknown type or a broken csv format are collected instead of stopping Unmarshal
+	column     string
+	marshalers map[string]*Marshaler
+}
+
+// NewDispatcher returns a new Dispatcher with the discriminator column column.
+func NewDispatcher(column string, r io.Reader) *Dispatcher {
+	return &Dispatcher{Reader: csv.NewReader(r), column: column, marshalers: map[string]*Marshaler{}}
+}
+
+// Register decodes the rows whose discriminator cell is value into the endpoint struct s.
+func (d *Dispatcher) Register(value string, s interface{}) error {
+	if _, ok := d.marshalers[value]; ok {
+		return fmt.Errorf("record type %s already registered", value)
+	}
+	schema, err := NewSchema(s)
+	if err != nil {
+		return err
+	}
+	d.marshalers[value] = NewMarshalerWithSchema(schema, nil)
+	return nil
+}
+
+// Unmarshal parses the csv file and calls fn with the type and the endpoint struct of every data
+// row in file order, an error of fn stops Unmarshal and is returned. Like with a Marshaler, rows
+// that cannot be decoded are returned as ParseErrors at the end, rows with an unknown type or a
+// broken csv format only with Lazy. Every registered type has to be complete in the header.
+func (d *Dispatcher) Unmarshal(fn func(value string, record interface{}) error) error {
+	header, err := d.Reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := stringSlice(header).index()
+	position, ok := columns[d.column]
+	if !ok {
+		return &csv.ParseError{Line: 1, Err: fmt.Errorf("%w: %s", ErrUnknownColumn, d.column)}
+	}
+	for _, m := range d.marshalers {
+		if err := m.resolveHeader(header); err != nil {
+			return err
+		}
+	}
+	errs := ParseErrors{}
+	for {
+		record, err := d.Reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if pe, ok := err.(*csv.ParseError); ok && d.Lazy {
+			errs = append(errs, *pe)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := d.Reader.FieldPos(0)
+		value := ""
+		if position < len(record) {
+			value = record[position]
+		}
+		m, ok := d.marshalers[value]
+		if !ok {
+			pe := &csv.ParseError{Line: line, Column: position, Err: fmt.Errorf("%w: %s", ErrUnknownRecordType, value)}
+			if !d.Lazy {
+				return pe
+			}
+			errs = append(errs, *pe)
+			continue
+		}
+		sPtr := reflect.New(reflect.TypeOf(m.endPointStruct))
+		perr := m.decode(sPtr.Interface(), record, line)
+		if perr != nil && errors.Is(perr.Err, ErrRowSkipped) {
+			continue
+		}
+		if perr != nil {
+			errs = append(errs, *perr)
+			continue
+		}
+		if err := fn(value, sPtr.Elem().Interface()); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// UnmarshalByType parses the csv file like Unmarshal and returns the endpoint structs per type.
+// The ParseErrors of single rows are returned together with the structs of the other rows.
+func (d *Dispatcher) UnmarshalByType() (map[string][]interface{}, error) {
+	structs := map[string][]interface{}{}
+	err := d.Unmarshal(func(value string, record interface{}) error {
+		structs[value] = append(structs[value], record)
+		return nil
+	})
+	if _, ok := err.(ParseErrors); err != nil && !ok {
+		return nil, err
+	}
+	return structs, err
+}
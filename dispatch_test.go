@@ -0,0 +1,92 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type DispatchOrder struct {
+	ID       int    `csv:"ID"`
+	Customer string `csv:"CUSTOMER"`
+}
+
+type DispatchItem struct {
+	ID      int    `csv:"ID"`
+	Article string `csv:"ARTICLE"`
+}
+
+func newTestDispatcher(t *testing.T, data string) *Dispatcher {
+	d := NewDispatcher("TYPE", strings.NewReader(data))
+	if err := d.Register("ORDER", DispatchOrder{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Register("ITEM", DispatchItem{}); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDispatcher(t *testing.T) {
+	data := "TYPE,ID,CUSTOMER,ARTICLE\nORDER,1,ACME,\nITEM,1,,screw\nITEM,1,,nut\nORDER,2,Initech,\nITEM,2,,stapler\n"
+	d := newTestDispatcher(t, data)
+	types := []string{}
+	if err := d.Unmarshal(func(value string, record interface{}) error {
+		types = append(types, value)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ORDER", "ITEM", "ITEM", "ORDER", "ITEM"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("wrong order - want: %v, got: %v", want, types)
+	}
+
+	structs, err := newTestDispatcher(t, data).UnmarshalByType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]interface{}{
+		"ORDER": {DispatchOrder{1, "ACME"}, DispatchOrder{2, "Initech"}},
+		"ITEM":  {DispatchItem{1, "screw"}, DispatchItem{1, "nut"}, DispatchItem{2, "stapler"}},
+	}
+	if !reflect.DeepEqual(structs, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, structs)
+	}
+
+	if err := d.Register("ORDER", DispatchOrder{}); err == nil {
+		t.Error("no error for a type registered twice, but it should")
+	}
+}
+
+func TestDispatcherErrors(t *testing.T) {
+	data := "TYPE,ID,CUSTOMER,ARTICLE\nORDER,1,ACME,\nNOTE,1,,\nITEM,x,,screw\nITEM,1,,nut\n"
+
+	_, err := newTestDispatcher(t, data).UnmarshalByType()
+	if !errors.Is(err, ErrUnknownRecordType) {
+		t.Errorf("wrong error without Lazy - want: %s, got: %v", ErrUnknownRecordType, err)
+	}
+
+	d := newTestDispatcher(t, data)
+	d.Lazy = true
+	structs, err := d.UnmarshalByType()
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("wrong errors with Lazy - want: 2 ParseErrors, got: %v", err)
+	}
+	if errs[0].Line != 3 || !errors.Is(&errs[0], ErrUnknownRecordType) || errs[1].Line != 4 {
+		t.Errorf("wrong errors: %v", errs)
+	}
+	if len(structs["ORDER"]) != 1 || len(structs["ITEM"]) != 1 {
+		t.Errorf("wrong result - want: 1 order and 1 item, got: %v", structs)
+	}
+
+	d = newTestDispatcher(t, "KIND,ID,CUSTOMER,ARTICLE\nORDER,1,ACME,\n")
+	if _, err := d.UnmarshalByType(); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrUnknownColumn, err)
+	}
+	d = newTestDispatcher(t, "TYPE,ID,CUSTOMER\nORDER,1,ACME\n")
+	if _, err := d.UnmarshalByType(); !errors.Is(err, ErrHeaderNotComplete) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrHeaderNotComplete, err)
+	}
+}
@@ -0,0 +1,182 @@
+package csv
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// validateEmptyQuoted checks that the emptyquoted option, e.g. `csv:"NOTE,emptyquoted"`, is only
+// used for strings, pointers to strings and driver.Valuer types like sql.NullString.
+func validateEmptyQuoted(typ reflect.Type, options tagOptions) error {
+	if _, ok := options["emptyquoted"]; !ok {
+		return nil
+	}
+	if typ.Kind() == reflect.String || typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.String || typ.Implements(valuerType) {
+		return nil
+	}
+	return fmt.Errorf("option emptyquoted requires a string, a pointer to a string or a driver.Valuer field, got: %s", typ)
+}
+
+// isEmptyQuoted checks if empty strings of the field are written as "", see the emptyquoted option.
+func (fieldInfo fieldInfo) isEmptyQuoted() bool {
+	_, ok := fieldInfo.options["emptyquoted"]
+	return ok
+}
+
+// hasEmptyQuoted checks if any field has the emptyquoted option.
+func (fieldInfos fieldInfos) hasEmptyQuoted() bool {
+	for _, fieldInfo := range fieldInfos {
+		if fieldInfo.isEmptyQuoted() {
+			return true
+		}
+	}
+	return false
+}
+
+// cell formats a single field value like format. For fields with the emptyquoted option null
+// reports a nil pointer or a driver.Valuer with nil Value, e.g. an invalid sql.NullString.
+func (w *Writer) cell(fieldInfo fieldInfo, v reflect.Value) (cell string, null bool, err error) {
+	if !fieldInfo.isEmptyQuoted() {
+		cell, err := w.format(fieldInfo, v)
+		return cell, false, err
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", true, nil
+		}
+		v = v.Elem()
+		fieldInfo.kind = v.Kind()
+	}
+	if v.Kind() == reflect.String {
+		cell, err := w.format(fieldInfo, v)
+		return cell, false, err
+	}
+	// copy the value to make a Value method with pointer receiver available
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	value, err := ptr.Interface().(driver.Valuer).Value()
+	if err != nil || value == nil {
+		return "", value == nil, err
+	}
+	switch value := value.(type) {
+	case string:
+		return value, false, nil
+	case []byte:
+		return string(value), false, nil
+	case int64:
+		return strconv.FormatInt(value, 10), false, nil
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64), false, nil
+	case bool:
+		return strconv.FormatBool(value), false, nil
+	case time.Time:
+		if len(fieldInfo.layout) > 0 {
+			return value.Format(fieldInfo.layout), false, nil
+		}
+		return value.Format(time.RFC3339Nano), false, nil
+	}
+	return "", false, fmt.Errorf("%w: driver value of type %T", ErrUnsupportedCSVType, value)
+}
+
+// startQuoting switches to the own quoting of writeQuoted if a field has the emptyquoted option,
+// encoding/csv writes empty cells without quotes.
+func (w *Writer) startQuoting() error {
+	if !w.fieldInfos.hasEmptyQuoted() {
+		return nil
+	}
+	if w.tsv != nil {
+		return errors.New("option emptyquoted requires quoted cells, NewTSVWriter writes them literally")
+	}
+	if c := w.Writer.Comma; c == '"' || c == '\r' || c == '\n' || c == utf8.RuneError || !utf8.ValidRune(c) {
+		return fmt.Errorf("invalid field delimiter: %q", c)
+	}
+	w.quoting = bufio.NewWriter(w.out)
+	return nil
+}
+
+// quoteEmptyRecord switches to the own quoting of writeQuoted for a record of one empty cell,
+// encoding/csv writes it as empty line, which is skipped when reading.
+func (w *Writer) quoteEmptyRecord() error {
+	if w.quoting != nil {
+		return nil
+	}
+	w.Writer.Flush()
+	if err := w.Writer.Error(); err != nil {
+		return err
+	}
+	w.quoting = bufio.NewWriter(w.out)
+	return nil
+}
+
+// writeQuoted writes record like encoding/csv, but quotes the empty cells of quoted as "".
+func (w *Writer) writeQuoted(record []string, quoted []bool) error {
+	for i, cell := range record {
+		if i > 0 {
+			if _, err := w.quoting.WriteRune(w.Writer.Comma); err != nil {
+				return err
+			}
+		}
+		if !w.needsQuotes(cell) && (i >= len(quoted) || !quoted[i]) {
+			if _, err := w.quoting.WriteString(cell); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.quoting.WriteByte('"'); err != nil {
+			return err
+		}
+		for _, r := range cell {
+			var err error
+			switch r {
+			case '"':
+				_, err = w.quoting.WriteString(`""`)
+			case '\r':
+				if !w.Writer.UseCRLF {
+					err = w.quoting.WriteByte('\r')
+				}
+			case '\n':
+				if w.Writer.UseCRLF {
+					_, err = w.quoting.WriteString("\r\n")
+				} else {
+					err = w.quoting.WriteByte('\n')
+				}
+			default:
+				_, err = w.quoting.WriteRune(r)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := w.quoting.WriteByte('"'); err != nil {
+			return err
+		}
+	}
+	if w.Writer.UseCRLF {
+		_, err := w.quoting.WriteString("\r\n")
+		return err
+	}
+	return w.quoting.WriteByte('\n')
+}
+
+// needsQuotes reports if cell has to be quoted by RFC 4180, with the same rules as encoding/csv:
+// cells with the separator, quotes, line breaks or a leading space and the cell \. are quoted.
+func (w *Writer) needsQuotes(cell string) bool {
+	if cell == "" {
+		return false
+	}
+	if cell == `\.` || strings.ContainsRune(cell, w.Writer.Comma) || strings.ContainsAny(cell, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(cell)
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,162 @@
+package csv
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"testing"
+)
+
+type EmptyQuotedStruct struct {
+	Name  string         `csv:"NAME"`
+	Note  string         `csv:"NOTE,emptyquoted"`
+	Alias *string        `csv:"ALIAS,emptyquoted"`
+	Code  sql.NullString `csv:"CODE,emptyquoted"`
+	Count sql.NullInt64  `csv:"COUNT,emptyquoted"`
+}
+
+func TestEmptyQuoted(t *testing.T) {
+	empty, alias := "", "b, \"c\""
+	tt := map[string]struct {
+		s      EmptyQuotedStruct
+		crlf   bool
+		comma  rune
+		output string
+	}{
+		"empty strings": {
+			s:      EmptyQuotedStruct{Alias: &empty, Code: sql.NullString{Valid: true}, Count: sql.NullInt64{Valid: true}},
+			output: "NAME,NOTE,ALIAS,CODE,COUNT\n,\"\",\"\",\"\",0\n",
+		},
+		"null values": {
+			s:      EmptyQuotedStruct{Name: "a"},
+			output: "NAME,NOTE,ALIAS,CODE,COUNT\na,\"\",,,\n",
+		},
+		"values": {
+			s:      EmptyQuotedStruct{Name: " a", Note: "x\ny", Alias: &alias, Code: sql.NullString{String: `\.`, Valid: true}, Count: sql.NullInt64{Int64: 3, Valid: true}},
+			output: "NAME,NOTE,ALIAS,CODE,COUNT\n\" a\",\"x\ny\",\"b, \"\"c\"\"\",\"\\.\",3\n",
+		},
+		"crlf": {
+			s:      EmptyQuotedStruct{Name: "a", Note: "x\ny"},
+			crlf:   true,
+			output: "NAME,NOTE,ALIAS,CODE,COUNT\r\na,\"x\r\ny\",,,\r\n",
+		},
+		"semicolon": {
+			s:      EmptyQuotedStruct{Name: "a;b", Note: "a,b"},
+			comma:  ';',
+			output: "NAME;NOTE;ALIAS;CODE;COUNT\n\"a;b\";a,b;;;\n",
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, err := NewWriter(EmptyQuotedStruct{}, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Writer.UseCRLF = tc.crlf
+			if tc.comma != 0 {
+				w.Writer.Comma = tc.comma
+			}
+			if err := w.Marshal([]interface{}{tc.s}); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.output {
+				t.Errorf("wrong output - want: %q, got: %q", tc.output, buf.String())
+			}
+		})
+	}
+}
+
+// Apart from the empty cells of emptyquoted fields, writeQuoted quotes like encoding/csv.
+func TestEmptyQuotedSameAsEncodingCSV(t *testing.T) {
+	records := [][]string{
+		{"NAME", "NOTE", "ALIAS", "CODE", "COUNT"},
+		{"", "a", " b", "\tc", "d\re"},
+		{`\.`, `"`, "f,g", "h\ni", "\u00a0j"},
+	}
+	for _, crlf := range []bool{false, true} {
+		want, got := &bytes.Buffer{}, &bytes.Buffer{}
+		cw := csv.NewWriter(want)
+		cw.UseCRLF = crlf
+		if err := cw.WriteAll(records); err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewWriter(EmptyQuotedStruct{}, got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Writer.UseCRLF = crlf
+		if err := w.start(); err != nil {
+			t.Fatal(err)
+		}
+		for _, record := range records {
+			if err := w.writeRecord(record, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.quoting.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if want.String() != got.String() {
+			t.Errorf("wrong output with crlf %v - want: %q, got: %q", crlf, want.String(), got.String())
+		}
+	}
+}
+
+// pointerValuer implements driver.Valuer with a pointer receiver.
+type pointerValuer struct {
+	s     string
+	valid bool
+}
+
+func (p *pointerValuer) Value() (driver.Value, error) {
+	if !p.valid {
+		return nil, nil
+	}
+	return p.s, nil
+}
+
+func TestEmptyQuotedPointerReceiver(t *testing.T) {
+	type s struct {
+		ID   int            `csv:"ID"`
+		Name *pointerValuer `csv:"NAME,emptyquoted"`
+	}
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(s{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	structs := []interface{}{s{1, nil}, s{2, &pointerValuer{}}, s{3, &pointerValuer{valid: true}}, s{4, &pointerValuer{s: "a", valid: true}}}
+	if err := w.Marshal(structs); err != nil {
+		t.Fatal(err)
+	}
+	if want := "ID,NAME\n1,\n2,\n3,\"\"\n4,a\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+}
+
+func TestEmptyQuotedErrors(t *testing.T) {
+	if _, err := NewWriter(struct {
+		F int `csv:"F,emptyquoted"`
+	}{}, &bytes.Buffer{}); err == nil {
+		t.Error("expected error for emptyquoted int field")
+	}
+
+	w, err := NewTSVWriter(EmptyQuotedStruct{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal([]interface{}{EmptyQuotedStruct{}}); err == nil {
+		t.Error("expected error for emptyquoted field in tab-separated file")
+	}
+
+	w, err = NewWriter(EmptyQuotedStruct{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Writer.Comma = '"'
+	if err := w.Marshal([]interface{}{EmptyQuotedStruct{}}); err == nil {
+		t.Error("expected error for invalid field delimiter")
+	}
+}
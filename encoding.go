@@ -0,0 +1,110 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// bom is the UTF-8 byte order mark written with WriteBOM.
+const bom = "\xef\xbb\xbf"
+
+var (
+	ErrUnknownEncoding = errors.New("unknown output encoding")
+	ErrUnrepresentable = errors.New("character not representable in output encoding")
+)
+
+// encoders are the supported output encodings besides UTF-8 by lower case name, they return
+// false for runes without representation.
+var encoders = map[string]func(r rune) (byte, bool){
+	"windows-1252": encodeWindows1252,
+	"cp1252":       encodeWindows1252,
+}
+
+// windows1252 maps the runes of the bytes 0x80 to 0x9f of windows-1252, the other bytes are the
+// same as in ISO-8859-1. The bytes 0x81, 0x8d, 0x8f, 0x90 and 0x9d are undefined.
+var windows1252 = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+func encodeWindows1252(r rune) (byte, bool) {
+	if r < 0x80 || r >= 0xa0 && r <= 0xff {
+		return byte(r), true
+	}
+	b, ok := windows1252[r]
+	return b, ok
+}
+
+// outputWriter encodes the UTF-8 output of a Writer, see OutputEncoding.
+type outputWriter struct {
+	w       io.Writer
+	encode  func(r rune) (byte, bool) // nil for UTF-8
+	partial []byte                    // incomplete rune at the end of the last Write
+}
+
+func (o *outputWriter) Write(p []byte) (int, error) {
+	if o.encode == nil {
+		return o.w.Write(p)
+	}
+	text := append(o.partial, p...)
+	encoded := make([]byte, 0, len(text))
+	for len(text) > 0 && utf8.FullRune(text) {
+		r, size := utf8.DecodeRune(text)
+		b, ok := o.encode(r)
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", ErrUnrepresentable, r)
+		}
+		encoded = append(encoded, b)
+		text = text[size:]
+	}
+	o.partial = append([]byte{}, text...)
+	if _, err := o.w.Write(encoded); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// check checks that all characters of s can be encoded.
+func (o *outputWriter) check(s string) error {
+	if o.encode == nil {
+		return nil
+	}
+	for _, r := range s {
+		if _, ok := o.encode(r); !ok {
+			return fmt.Errorf("%w: %q", ErrUnrepresentable, r)
+		}
+	}
+	return nil
+}
+
+// start prepares the output before anything is written: it selects the quoting of emptyquoted
+// fields and the OutputEncoding and writes the byte order mark of WriteBOM.
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	if err := w.startQuoting(); err != nil {
+		return err
+	}
+	if name := strings.ToLower(w.OutputEncoding); name != "" && name != "utf-8" && name != "utf8" {
+		encode, ok := encoders[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownEncoding, w.OutputEncoding)
+		}
+		if w.WriteBOM {
+			return fmt.Errorf("byte order mark requires utf-8 output, got: %s", w.OutputEncoding)
+		}
+		w.out.encode = encode
+	}
+	if !w.WriteBOM {
+		return nil
+	}
+	_, err := io.WriteString(w.out, bom)
+	return err
+}
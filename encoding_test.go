@@ -0,0 +1,81 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type EncodingStruct struct {
+	Name  string `csv:"NAME"`
+	Price string `csv:"PRICE"`
+}
+
+func TestWriteBOM(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(EncodingStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteBOM = true
+	if err := w.WriteComments([]string{"export"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal([]interface{}{EncodingStruct{Name: "Müller", Price: "5 €"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\xef\xbb\xbf# export\nNAME,PRICE\nMüller,5 €\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+}
+
+func TestOutputEncoding(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(EncodingStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.OutputEncoding = "Windows-1252"
+	// long enough to split runes between the writes of the buffered csv.Writer
+	long := strings.Repeat("é", 5000)
+	structs := []interface{}{EncodingStruct{Name: "Müller", Price: "5 €"}, EncodingStruct{Name: long, Price: "“1”"}}
+	if err := w.Marshal(structs); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME,PRICE\nM\xfcller,5 \x80\n" + strings.Repeat("\xe9", 5000) + ",\x931\x94\n"
+	if buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want[:40], buf.String()[:40])
+	}
+
+	// unrepresentable characters fail with the record and field
+	if w, err = NewWriter(EncodingStruct{}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	w.OutputEncoding = "windows-1252"
+	err = w.Marshal([]interface{}{EncodingStruct{Name: "a"}, EncodingStruct{Name: "b", Price: "5 ₿"}})
+	var we *WriteError
+	if !errors.As(err, &we) || !errors.Is(err, ErrUnrepresentable) || we.Record != 1 || we.Field != "Price" {
+		t.Errorf("wrong error - want: %s in record 1 field Price, got: %v", ErrUnrepresentable, err)
+	}
+
+	tt := map[string]struct {
+		encoding string
+		bom      bool
+		wantErr  bool
+	}{
+		"unknown":   {encoding: "latin-5", wantErr: true},
+		"bom":       {encoding: "cp1252", bom: true, wantErr: true},
+		"utf-8 bom": {encoding: "UTF-8", bom: true},
+	}
+	for name, tc := range tt {
+		w, err := NewWriter(EncodingStruct{}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.OutputEncoding, w.WriteBOM = tc.encoding, tc.bom
+		if err := w.Flush(); (err != nil) != tc.wantErr {
+			t.Errorf("wrong error for %s - want error: %v, got: %v", name, tc.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package csv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	maxErrorLines     = 50   // maximum number of errors ParseErrors.Error lists
+	maxErrorExamples  = 5    // maximum number of examples per ErrorGroup
+	defaultMaxRawSize = 1024 // default of Marshaler.MaxRawSize
+)
+
+// FieldError describes why the cell of a field could not be decoded.
+type FieldError struct {
+	Source string // label of WithSource, empty if not set
+	Field  string // name of the struct field
+	Header string // csv header name of the field
+	Value  string // raw cell
+	Raw    string // raw line of the record, only set with CaptureRawOnError
+	Err    error
+}
+
+func newFieldError(fieldInfo fieldInfo, value string, err error) *FieldError {
+	return &FieldError{Field: fieldInfo.fieldName, Header: fieldInfo.headerName, Value: value, Err: err}
+}
+
+// fieldError returns the FieldError of pe, other errors are wrapped in a FieldError.
+func fieldError(pe *csv.ParseError) *FieldError {
+	var fe *FieldError
+	if !errors.As(pe.Err, &fe) {
+		fe = &FieldError{Err: pe.Err}
+		pe.Err = fe
+	}
+	return fe
+}
+
+// Error returns the FieldError as string
+func (e *FieldError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("source:%s,field:%s,header:%s,err:%s", e.Source, e.Field, e.Header, e.Err)
+	}
+	return fmt.Sprintf("field:%s,header:%s,err:%s", e.Field, e.Header, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// HeaderError lists the differences between a csv header and the endpoint struct.
+type HeaderError struct {
+	Missing    []string // csv tag names without column
+	Extra      []string // columns without csv tag
+	Duplicates []string // columns that appear more than once
+}
+
+// Error returns the HeaderError as string
+func (e *HeaderError) Error() string {
+	return fmt.Sprintf("invalid header: missing:%s,extra:%s,duplicates:%s",
+		strings.Join(e.Missing, ";"), strings.Join(e.Extra, ";"), strings.Join(e.Duplicates, ";"))
+}
+
+// ReadError reports a failure of the underlying reader.
+type ReadError struct {
+	Line   int   // line the reader failed on
+	Offset int64 // input offset of the last successfully read record
+	Err    error
+}
+
+// Error returns the ReadError as string
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("line:%d,offset:%d,err:%s", e.Line, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// ColumnCountError reports a data row with a different number of columns than the header. It
+// unwraps to csv.ErrFieldCount.
+type ColumnCountError struct {
+	Expected   int // number of columns of the header or Reader.FieldsPerRecord
+	Actual     int // number of columns of the row
+	HeaderLine int // line of the header
+}
+
+// Error returns the ColumnCountError as string
+func (e *ColumnCountError) Error() string {
+	return fmt.Sprintf("%s: %d instead of %d, header on line %d", csv.ErrFieldCount, e.Actual, e.Expected, e.HeaderLine)
+}
+
+// Unwrap returns csv.ErrFieldCount
+func (e *ColumnCountError) Unwrap() error {
+	return csv.ErrFieldCount
+}
+
+// ParseErrors is a slice of csv.ParseError
+type ParseErrors []csv.ParseError
+
+// Error returns te ParseErrors as string, errors after the first maxErrorLines are summarized.
+func (errs ParseErrors) Error() string {
+	s := ""
+	for i, err := range errs {
+		if i == maxErrorLines {
+			s = s + fmt.Sprintf("... and %d more errors\n", len(errs)-maxErrorLines)
+			break
+		}
+		s = s + fmt.Sprintf("line:%d,position:%d,err:%s\n", err.Line, err.Column, err.Err)
+	}
+	return s
+}
+
+// Len is the number of errors
+func (errs ParseErrors) Len() int {
+	return len(errs)
+}
+
+// Less orders the errors by line and column
+func (errs ParseErrors) Less(i, j int) bool {
+	if errs[i].Line != errs[j].Line {
+		return errs[i].Line < errs[j].Line
+	}
+	return errs[i].Column < errs[j].Column
+}
+
+// Swap swaps the errors with index i and j
+func (errs ParseErrors) Swap(i, j int) {
+	errs[i], errs[j] = errs[j], errs[i]
+}
+
+// Sort sorts the errors by line and column
+func (errs ParseErrors) Sort() {
+	sort.Stable(errs)
+}
+
+// Entries returns the errors as slice of csv.ParseError
+func (errs ParseErrors) Entries() []csv.ParseError {
+	return errs
+}
+
+// Severity returns "error"
+func (errs ParseErrors) Severity() string {
+	return "error"
+}
+
+// jsonError is the json representation of a csv.ParseError
+type jsonError struct {
+	Source  string `json:"source,omitempty"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Header  string `json:"header"`
+	Message string `json:"message"`
+	Raw     string `json:"raw,omitempty"`
+}
+
+// MarshalJSON returns the ParseErrors as json array
+func (errs ParseErrors) MarshalJSON() ([]byte, error) {
+	jsonErrors := make([]jsonError, 0, len(errs))
+	for _, err := range errs {
+		je := jsonError{Line: err.Line, Column: err.Column, Message: err.Err.Error()}
+		var fe *FieldError
+		if errors.As(err.Err, &fe) {
+			je.Source = fe.Source
+			je.Raw = fe.Raw
+			je.Header = fe.Header
+			je.Message = fe.Err.Error()
+		}
+		jsonErrors = append(jsonErrors, je)
+	}
+	return json.Marshal(jsonErrors)
+}
+
+// ErrorGroup summarizes identical errors of a column.
+type ErrorGroup struct {
+	Column int      // zero based column of the errors
+	Header string   // csv header name of the column, empty for errors of the csv format
+	Err    error    // innermost error, e.g strconv.ErrSyntax
+	Count  int      // number of errors
+	Lines  []int    // lines of the first errors
+	Values []string // raw cells of the first errors
+}
+
+// Summary groups the errors by column, header and innermost error. The groups are ordered by
+// descending count, then by column, each group lists up to 5 example lines and values.
+func (errs ParseErrors) Summary() []ErrorGroup {
+	sorted := append(ParseErrors{}, errs...)
+	sorted.Sort()
+	groups := []ErrorGroup{}
+	index := map[string]int{}
+	for _, err := range sorted {
+		group := ErrorGroup{Column: err.Column, Err: innermost(err.Err)}
+		value := ""
+		var fe *FieldError
+		if errors.As(err.Err, &fe) {
+			group.Header = fe.Header
+			value = fe.Value
+		}
+		key := fmt.Sprintf("%d,%s,%s", group.Column, group.Header, group.Err)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group)
+		}
+		groups[i].Count++
+		if len(groups[i].Lines) < maxErrorExamples {
+			groups[i].Lines = append(groups[i].Lines, err.Line)
+			groups[i].Values = append(groups[i].Values, value)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Column < groups[j].Column
+	})
+	return groups
+}
+
+// innermost unwraps err until the innermost error is reached.
+func innermost(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// ErrorRateError is returned if the ratio of failed rows exceeds the Marshaler's ErrorRateLimit.
+type ErrorRateError struct {
+	Rate         float64
+	Limit        float64
+	ColumnErrors map[string]int
+}
+
+// Error returns the ErrorRateError as string
+func (e *ErrorRateError) Error() string {
+	headers := make([]string, 0, len(e.ColumnErrors))
+	for header := range e.ColumnErrors {
+		headers = append(headers, header)
+	}
+	sort.Strings(headers)
+	s := fmt.Sprintf("%s: rate:%g,limit:%g", ErrErrorRateExceeded, e.Rate, e.Limit)
+	for _, header := range headers {
+		s = s + fmt.Sprintf(",%s:%d", header, e.ColumnErrors[header])
+	}
+	return s
+}
+
+// Unwrap returns ErrErrorRateExceeded
+func (e *ErrorRateError) Unwrap() error {
+	return ErrErrorRateExceeded
+}
@@ -0,0 +1,211 @@
+package csv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorsSort(t *testing.T) {
+	errs := ParseErrors{
+		{Line: 3, Column: 1},
+		{Line: 1, Column: 2},
+		{Line: 3, Column: 0},
+		{Line: 1, Column: 1},
+	}
+	errs.Sort()
+	want := [][2]int{{1, 1}, {1, 2}, {3, 0}, {3, 1}}
+	for i, err := range errs {
+		if err.Line != want[i][0] || err.Column != want[i][1] {
+			t.Errorf("wrong order at index %d - want: %v, got: line %d column %d", i, want[i], err.Line, err.Column)
+		}
+	}
+}
+
+func TestParseErrorsJSON(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	_, err = m.Unmarshal()
+	errs, ok := err.(ParseErrors)
+	if !ok {
+		t.Fatalf("wrong error - want ParseErrors, got: %v", err)
+	}
+	errs = append(errs, csv.ParseError{Line: 5, Column: 3, Err: csv.ErrFieldCount})
+	b, err := json.Marshal(errs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"line":3,"column":2,"header":"FIELD_2","message":"strconv.ParseBool: parsing \"notvalid\": invalid syntax"},` +
+		`{"line":5,"column":3,"header":"","message":"wrong number of fields"}]`
+	if string(b) != want {
+		t.Errorf("wrong json - want: %s, got: %s", want, b)
+	}
+}
+
+func TestParseErrorsErrorSummary(t *testing.T) {
+	errs := ParseErrors{}
+	for i := 0; i < maxErrorLines+10; i++ {
+		errs = append(errs, csv.ParseError{Line: i, Err: errors.New("invalid")})
+	}
+	lines := strings.Split(strings.TrimSpace(errs.Error()), "\n")
+	if len(lines) != maxErrorLines+1 {
+		t.Fatalf("wrong number of lines - want: %d, got: %d", maxErrorLines+1, len(lines))
+	}
+	if want := fmt.Sprintf("... and %d more errors", 10); lines[maxErrorLines] != want {
+		t.Errorf("wrong summary - want: %s, got: %s", want, lines[maxErrorLines])
+	}
+	if errs[:2].Error() != "line:0,position:0,err:invalid\nline:1,position:0,err:invalid\n" {
+		t.Errorf("wrong error for few errors: %q", errs[:2].Error())
+	}
+}
+
+// failingReader fails after n bytes have been read.
+type failingReader struct {
+	r   io.Reader
+	n   int
+	err error
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if f.n <= 0 {
+		return 0, f.err
+	}
+	if len(p) > f.n {
+		p = p[:f.n]
+	}
+	n, err := f.r.Read(p)
+	f.n -= n
+	return n, err
+}
+
+func TestParseErrorsSummary(t *testing.T) {
+	// wrongTypes with many rows: an invalid FIELD_2 in every second row, an invalid
+	// FIELD_1 in every third row and an overflowing FIELD_1 in row 7
+	data := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\n"
+	for i := 0; i < 20; i++ {
+		field1, field2 := fmt.Sprint(i), "true"
+		if i%2 == 1 {
+			field2 = "notvalid"
+		}
+		if i%3 == 0 {
+			field1 = fmt.Sprintf("x%d", i)
+		}
+		if i == 7 {
+			field1 = "99999999999999999999"
+		}
+		data += fmt.Sprintf("string%d;%s;%s;1.14\n", i, field1, field2)
+	}
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	_, err = m.Unmarshal()
+	errs, ok := err.(ParseErrors)
+	if !ok {
+		t.Fatalf("wrong error - want: ParseErrors, got: %v", err)
+	}
+	// decoding stops at the first error of a row
+	want := []ErrorGroup{
+		{Column: 1, Header: "FIELD_1", Err: strconv.ErrSyntax, Count: 7,
+			Lines: []int{2, 5, 8, 11, 14}, Values: []string{"x0", "x3", "x6", "x9", "x12"}},
+		{Column: 2, Header: "FIELD_2", Err: strconv.ErrSyntax, Count: 6,
+			Lines: []int{3, 7, 13, 15, 19}, Values: []string{"notvalid", "notvalid", "notvalid", "notvalid", "notvalid"}},
+		{Column: 1, Header: "FIELD_1", Err: strconv.ErrRange, Count: 1,
+			Lines: []int{9}, Values: []string{"99999999999999999999"}},
+	}
+	for i := 0; i < 3; i++ {
+		// the order must not depend on the order of the errors
+		if got := errs.Summary(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("wrong summary - want: %v, got: %v", want, got)
+		}
+		errs.Swap(0, len(errs)-1)
+	}
+}
+
+func TestUnmarshalReadError(t *testing.T) {
+	networkErr := errors.New("connection reset")
+	for _, lazy := range []bool{false, true} {
+		r := &failingReader{r: strings.NewReader(wrongTypes), n: 60, err: networkErr}
+		m, err := NewMarshaler(TestStruct{}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		m.Lazy = lazy
+		_, err = m.Unmarshal()
+		if !errors.Is(err, networkErr) {
+			t.Fatalf("lazy %t: wrong error - want: %s, got: %v", lazy, networkErr, err)
+		}
+		re, ok := err.(*ReadError)
+		if !ok {
+			t.Fatalf("lazy %t: wrong error - want: *ReadError, got: %T", lazy, err)
+		}
+		if re.Line == 0 || re.Offset == 0 || re.Offset > 60 {
+			t.Errorf("lazy %t: wrong context for read error: %s", lazy, re)
+		}
+	}
+}
+
+func TestValidateHeader(t *testing.T) {
+	var validateHeaderTests = map[string]struct {
+		header []string
+		err    *HeaderError
+	}{
+		"valid":      {[]string{"FIELD_3", "FIELD_0", "FIELD_1", "FIELD_2"}, nil},
+		"missing":    {[]string{"FIELD_0", "FIELD_1"}, &HeaderError{Missing: []string{"FIELD_2", "FIELD_3"}}},
+		"extra":      {[]string{"FIELD_0", "FIELD_1", "JUNK", "FIELD_2", "FIELD_3"}, &HeaderError{Extra: []string{"JUNK"}}},
+		"duplicates": {[]string{"FIELD_0", "FIELD_1", "FIELD_2", "FIELD_3", "FIELD_1", "FIELD_1"}, &HeaderError{Duplicates: []string{"FIELD_1"}}},
+		"all":        {[]string{"FIELD_0", "FIELD_0", "X"}, &HeaderError{Missing: []string{"FIELD_1", "FIELD_2", "FIELD_3"}, Extra: []string{"X"}, Duplicates: []string{"FIELD_0"}}},
+	}
+	for name, test := range validateHeaderTests {
+		err := ValidateHeader(TestStruct{}, test.header)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("wrong error for test '%s' - want: nil, got: %s", name, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(err, test.err) {
+			t.Errorf("wrong error for test '%s' - want: %s, got: %v", name, test.err, err)
+		}
+	}
+	if err := ValidateHeader("no struct", nil); err != ErrNoStruct {
+		t.Errorf("wrong error for invalid struct - want: %s, got: %v", ErrNoStruct, err)
+	}
+}
+
+func TestValidateHeaderFromReader(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(notEnoughHeaders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	err = m.ValidateHeaderFromReader()
+	if he, ok := err.(*HeaderError); !ok || !reflect.DeepEqual(he.Missing, []string{"FIELD_3"}) {
+		t.Errorf("wrong error - want missing FIELD_3, got: %v", err)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if err := m.ValidateHeaderFromReader(); err != nil {
+		t.Fatalf("error in ValidateHeaderFromReader: %s", err)
+	}
+	// the header is not consumed
+	result, _ := m.Unmarshal()
+	if len(result) != 2 {
+		t.Errorf("wrong number of records after header validation - want: %d, got: %d", 2, len(result))
+	}
+}
@@ -0,0 +1,218 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Semicolon separated export with comment lines before the header.
+func ExampleMarshaler_Unmarshal() {
+	data := "# exported by the billing system\n# do not edit\nFIELD_0;FIELD_1;FIELD_2;FIELD_3\na;1;true;1.5\nb;2;false;2.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithComment('#'))
+	if err != nil {
+		panic(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("header on line", m.HeaderLine())
+	for _, item := range result {
+		fmt.Printf("%+v\n", item.(TestStruct))
+	}
+	// Output:
+	// header on line 3
+	// {Field0:a Field1:1 Field2:true Field3:1.5 IngnoredStruct:false}
+	// {Field0:b Field1:2 Field2:false Field3:2.5 IngnoredStruct:false}
+}
+
+// With Lazy, rows that cannot be decoded are reported together and the valid rows are kept.
+func ExampleMarshaler_Unmarshal_lazy() {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,x,true,2.5\nc,3,maybe,3.5\nd,4,false,4.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	m.Lazy = true
+	result, err := m.Unmarshal()
+	var errs ParseErrors
+	if errors.As(err, &errs) {
+		fmt.Print(errs)
+	}
+	report := m.Report()
+	fmt.Println("kept:", len(result), "rows:", report.Rows, "failed:", report.FailedRows)
+	// Output:
+	// line:3,position:1,err:field:Field1,header:FIELD_1,err:strconv.ParseInt: parsing "x": invalid syntax
+	// line:4,position:2,err:field:Field2,header:FIELD_2,err:strconv.ParseBool: parsing "maybe": invalid syntax
+	// kept: 2 rows: 4 failed: 2
+}
+
+// UnmarshalSlice decodes directly into a typed slice.
+func ExampleMarshaler_UnmarshalSlice() {
+	data := "FIELD_3,FIELD_2,FIELD_1,FIELD_0\n1.5,true,1,a\n2.5,false,2,b\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	structs := []TestStruct{}
+	if err := m.UnmarshalSlice(&structs); err != nil {
+		panic(err)
+	}
+	for _, s := range structs {
+		fmt.Println(s.Field0, s.Field1, s.Field2, s.Field3)
+	}
+	// Output:
+	// a 1 true 1.5
+	// b 2 false 2.5
+}
+
+// Records written by a Writer are read back unchanged by a Marshaler.
+func ExampleWriter_Marshal() {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(TestStruct{}, buf)
+	if err != nil {
+		panic(err)
+	}
+	w.Writer.Comma = ';'
+	structs := []interface{}{
+		TestStruct{Field0: "a;b", Field1: 1, Field2: true, Field3: 1.5},
+		TestStruct{Field0: `"quoted"`, Field1: -2, Field3: 0.25},
+	}
+	if err := w.Marshal(structs); err != nil {
+		panic(err)
+	}
+	fmt.Print(buf.String())
+
+	m, err := NewMarshaler(TestStruct{}, buf)
+	if err != nil {
+		panic(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("round trip:", fmt.Sprint(result) == fmt.Sprint(structs))
+	// Output:
+	// FIELD_0;FIELD_1;FIELD_2;FIELD_3
+	// "a;b";1;true;1.5
+	// """quoted""";-2;false;0.25
+	// round trip: true
+}
+
+// Time fields are parsed with the layout of the format option, empty cells take the default.
+func Example_timeAndDefault() {
+	type Booking struct {
+		Day    time.Time `csv:"DAY,format=02.01.2006"`
+		Room   string    `csv:"ROOM,default=lobby"`
+		Guests int       `csv:"GUESTS,default=1"`
+	}
+	data := "DAY,ROOM,GUESTS\n24.12.2024,,\n31.12.2024,hall,40\n"
+	m, err := NewMarshaler(Booking{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		panic(err)
+	}
+	for _, item := range result {
+		b := item.(Booking)
+		fmt.Println(b.Day.Format("2006-01-02"), b.Room, b.Guests)
+	}
+	// Output:
+	// 2024-12-24 lobby 1
+	// 2024-12-31 hall 40
+}
+
+// Old header names are mapped to the current csv tags, the used aliases are reported.
+func ExampleWithHeaderAliases() {
+	data := "FIELD_0,OLD_1,FIELD_2,FIELD_3\na,1,true,1.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithHeaderAliases(map[string]string{"OLD_1": "FIELD_1"}))
+	if err != nil {
+		panic(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result[0].(TestStruct).Field1, m.Report().Aliases)
+	// Output:
+	// 1 map[OLD_1:FIELD_1]
+}
+
+// WhereColumn decodes only the rows of one record type, the others are counted as filtered.
+func ExampleWhereColumn() {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\nitem,1,true,1.5\ntotal,1,true,1.5\nitem,2,false,2.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WhereColumn("FIELD_0", "item"))
+	if err != nil {
+		panic(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("items:", len(result), "filtered:", m.Report().FilteredRows)
+	// Output:
+	// items: 2 filtered: 1
+}
+
+// Next streams the records one by one, Lazy errors are returned by Err at the end.
+func ExampleMarshaler_Next() {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,x,true,2.5\nc,3,false,3.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	m.Lazy = true
+	for m.Next() {
+		fmt.Println(m.Record().(TestStruct).Field0)
+	}
+	fmt.Print(m.Err())
+	// Output:
+	// a
+	// c
+	// line:3,position:1,err:field:Field1,header:FIELD_1,err:strconv.ParseInt: parsing "x": invalid syntax
+}
+
+// Summary groups the errors of a Lazy parse by column and cause.
+func ExampleParseErrors_Summary() {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,x,true,1.5\nb,y,true,2.5\nc,3,no,3.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		panic(err)
+	}
+	for _, group := range errs.Summary() {
+		fmt.Println(group.Header, group.Count, group.Lines, group.Values)
+	}
+	// Output:
+	// FIELD_1 2 [2 3] [x y]
+	// FIELD_2 1 [4] [no]
+}
+
+// Tab-separated files take quotes literally, BackslashEscapes decodes escaped tabs.
+func ExampleNewTSVMarshaler() {
+	data := "FIELD_0\tFIELD_1\tFIELD_2\tFIELD_3\n\"a\"\\tb\t1\ttrue\t1.5\n"
+	m, err := NewTSVMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	m.BackslashEscapes = true
+	result, err := m.Unmarshal()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%q\n", result[0].(TestStruct).Field0)
+	// Output:
+	// "\"a\"\tb"
+}
@@ -0,0 +1,107 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseCols parses the cols option of fixed-width files, the one based and inclusive rune
+// positions of the cell, e.g. `csv:"NAME,cols=1-10"`. It returns nil without cols option.
+func parseCols(options tagOptions) ([]int, error) {
+	spec, ok := options["cols"]
+	if !ok {
+		return nil, nil
+	}
+	if _, ok := options["glob"]; ok {
+		return nil, fmt.Errorf("glob fields cannot have cols")
+	}
+	bounds := strings.SplitN(spec, "-", 2)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("invalid cols %s, expected start-end", spec)
+	}
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of cols %s: %s", spec, err)
+	}
+	end, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of cols %s: %s", spec, err)
+	}
+	if start < 1 || end < start {
+		return nil, fmt.Errorf("cols %s out of range", spec)
+	}
+	return []int{start, end}, nil
+}
+
+// isFixedWidth checks if the fields are read from a fixed-width file. Either all or no fields
+// have the cols option and the cols of the fields do not overlap.
+func (fieldInfos fieldInfos) isFixedWidth() (bool, error) {
+	if len(fieldInfos) == 0 || fieldInfos[0].cols == nil {
+		for _, fieldInfo := range fieldInfos {
+			if fieldInfo.cols != nil {
+				return false, fmt.Errorf("invalid csv tag for field %s: cols requires cols for all fields", fieldInfo.fieldName)
+			}
+		}
+		return false, nil
+	}
+	for i, fieldInfo := range fieldInfos {
+		if fieldInfo.cols == nil {
+			return false, fmt.Errorf("invalid csv tag for field %s: missing cols of fixed-width field", fieldInfo.fieldName)
+		}
+		if fieldInfo.isComposite() {
+			return false, fmt.Errorf("invalid csv tag for field %s: composite fields cannot have cols", fieldInfo.fieldName)
+		}
+		for _, other := range fieldInfos[:i] {
+			if fieldInfo.cols[0] <= other.cols[1] && other.cols[0] <= fieldInfo.cols[1] {
+				return false, fmt.Errorf("invalid csv tag for field %s: cols %s overlap cols %s of field %s",
+					fieldInfo.fieldName, fieldInfo.options["cols"], other.options["cols"], other.fieldName)
+			}
+		}
+	}
+	return true, nil
+}
+
+// fixedWidthHeader returns the implied header of a fixed-width file, the header names in field order.
+func (m *Marshaler) fixedWidthHeader() []string {
+	header := make([]string, 0, len(m.allFieldInfos))
+	for _, fieldInfo := range m.allFieldInfos {
+		header = append(header, fieldInfo.headerName)
+	}
+	return header
+}
+
+// fixedWidthSplitter returns the function that translates the lines of a fixed-width file for
+// encoding/csv. The cells are sliced by the cols of the fields in field order and right-trimmed,
+// cells beyond the end of short lines are empty.
+func (m *Marshaler) fixedWidthSplitter() func(line []byte) []byte {
+	comma, comment := string(m.Reader.Comma), m.Reader.Comment
+	return func(line []byte) []byte {
+		if comment != 0 && bytes.HasPrefix(line, []byte(string(comment))) {
+			return line
+		}
+		body := bytes.TrimSuffix(line, []byte("\n"))
+		body = bytes.TrimSuffix(body, []byte("\r"))
+		if len(body) == 0 {
+			return line
+		}
+		runes := []rune(string(body))
+		translated := make([]byte, 0, len(line)+len(m.allFieldInfos))
+		for i, fieldInfo := range m.allFieldInfos {
+			if i > 0 {
+				translated = append(translated, comma...)
+			}
+			start, end := fieldInfo.cols[0]-1, fieldInfo.cols[1]
+			if start > len(runes) {
+				start = len(runes)
+			}
+			if end > len(runes) {
+				end = len(runes)
+			}
+			cell := strings.TrimRight(string(runes[start:end]), " ")
+			translated = appendCell(translated, []byte(cell), comma)
+		}
+		return append(translated, line[len(body):]...)
+	}
+}
@@ -0,0 +1,67 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type FixedWidthStruct struct {
+	Name   string  `csv:"NAME,cols=1-10"`
+	Amount int     `csv:"AMOUNT,cols=11-15,default=0"`
+	Rate   float64 `csv:"RATE,cols=16-20"`
+}
+
+func TestUnmarshalFixedWidth(t *testing.T) {
+	data := "Zürich    000421.5  \nBern      xx    2.5\nGenève         \"3\"\nBasel          0.5\n"
+	m, err := NewMarshaler(FixedWidthStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	result, err := m.Unmarshal()
+	want := []interface{}{
+		FixedWidthStruct{Name: "Zürich", Amount: 42, Rate: 1.5},
+		FixedWidthStruct{Name: "Basel", Amount: 0, Rate: 0.5},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("wrong errors - want: 2 ParseErrors, got: %v", err)
+	}
+	if errs[0].Line != 2 || errs[0].Column != 1 || errs[1].Line != 3 || errs[1].Column != 2 {
+		t.Errorf("wrong errors - want: line 2 column 1 and line 3 column 2, got: %v", errs)
+	}
+	if report := m.Report(); report.Rows != 4 || report.FailedRows != 2 {
+		t.Errorf("wrong report - want: 4 rows and 2 failed, got: %d and %d", report.Rows, report.FailedRows)
+	}
+}
+
+func TestFixedWidthInvalidTags(t *testing.T) {
+	type Overlap struct {
+		A string `csv:"A,cols=1-10"`
+		B string `csv:"B,cols=10-12"`
+	}
+	type Partial struct {
+		A string `csv:"A,cols=1-10"`
+		B string `csv:"B"`
+	}
+	type Range struct {
+		A string `csv:"A,cols=0-10"`
+	}
+	type Reversed struct {
+		A string `csv:"A,cols=10-1"`
+	}
+	type Syntax struct {
+		A string `csv:"A,cols=10"`
+	}
+	tt := map[string]interface{}{"overlap": Overlap{}, "partial": Partial{}, "range": Range{}, "reversed": Reversed{}, "syntax": Syntax{}}
+	for name, s := range tt {
+		if _, err := NewSchema(s); err == nil {
+			t.Errorf("%s: no error, but it should", name)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// parseLayout returns the time layout of the format option, e.g. `csv:"DATE,format=02.01.2006"`.
+// The option is only valid for time.Time fields.
+func parseLayout(typ reflect.Type, options tagOptions) (string, error) {
+	layout, ok := options["format"]
+	if !ok {
+		return "", nil
+	}
+	if typ != timeType {
+		return "", fmt.Errorf("option format requires a time.Time field, got: %s", typ)
+	}
+	if len(layout) == 0 {
+		return "", errors.New("empty format")
+	}
+	return layout, nil
+}
@@ -0,0 +1,46 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type FormatStruct struct {
+	Date time.Time `csv:"DATE,format=02.01.2006"`
+}
+
+func TestFormat(t *testing.T) {
+	m, err := NewMarshaler(FormatStruct{}, strings.NewReader("DATE\n24.12.2020\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC)
+	if got := result[0].(FormatStruct).Date; !got.Equal(want) {
+		t.Errorf("wrong date - want: %s, got: %s", want, got)
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(FormatStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal(result); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "DATE\n24.12.2020\n" {
+		t.Errorf("wrong output - want: %q, got: %q", "DATE\n24.12.2020\n", buf.String())
+	}
+
+	type NoTime struct {
+		Date string `csv:"DATE,format=02.01.2006"`
+	}
+	if _, err := NewSchema(NoTime{}); err == nil {
+		t.Error("no error for format on a string field, but it should")
+	}
+}
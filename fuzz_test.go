@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are inputs of the other tests used as seed corpus.
+var fuzzSeeds = []string{
+	"FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,false,2.5\n",
+	"FIELD_0;FIELD_2;FIELD_1;FIELD_3\nstring1;true;1\nstring2;true;2;1.14\n",
+	"FIELD_0,FIELD_1,FIELD_2,FIELD_3\n\"a\nb\",1,true,1.5\n\"unterminated,2,true,2.5\nc,3,true,3.5\n",
+	"# comment\nFIELD_3,FIELD_2,FIELD_1,FIELD_0\n1.5,true,1,a\r\n",
+	"FIELD_0,FIELD_1,FIELD_2,FIELD_3,\na,1,true,1.5,\nb,x,maybe,y,z\n",
+	"FIELD_0,FIELD_0,FIELD_1\n",
+	"\xef\xbb\xbfFIELD_0,FIELD_1,FIELD_2,FIELD_3\na,\"1\"x,true,1.5\n",
+	"a,1,true,1.5\n",
+	"\"\n0", // unterminated quote in the header
+	"",
+}
+
+// documentedError checks that err is one of the errors Unmarshal documents.
+func documentedError(err error) bool {
+	var (
+		pe  *csv.ParseError
+		pes ParseErrors
+		re  *ReadError
+		he  *HeaderError
+	)
+	return errors.As(err, &pe) || errors.As(err, &pes) || errors.As(err, &re) || errors.As(err, &he)
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed), false, uint8(0))
+		f.Add([]byte(seed), true, uint8(0xff))
+	}
+	f.Fuzz(func(t *testing.T, data []byte, lazy bool, flags uint8) {
+		opts := []Option{}
+		if flags&1 != 0 {
+			opts = append(opts, WithComment('#'))
+		}
+		m, err := NewMarshaler(TestStruct{}, bytes.NewReader(data), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Lazy = lazy
+		m.IgnoreExtraColumns = flags&4 != 0
+		m.DetectTrailingEmptyColumn = flags&8 != 0
+		if flags&16 != 0 {
+			m.Reader.FieldsPerRecord = -1
+		}
+		m.CaptureRawOnError = true
+		m.MaxRawSize = 16
+		result, err := m.Unmarshal()
+		if err != nil && !documentedError(err) {
+			t.Fatalf("undocumented error %T: %v", err, err)
+		}
+		lines := bytes.Count(data, []byte("\n")) + 1
+		var errs ParseErrors
+		errors.As(err, &errs)
+		if len(result)+len(errs) > lines {
+			t.Fatalf("more records and errors than lines - lines: %d, records: %d, errors: %d", lines, len(result), len(errs))
+		}
+		for _, e := range errs {
+			var fe *FieldError
+			if errors.As(e.Err, &fe) && len(fe.Raw) > m.MaxRawSize {
+				t.Fatalf("raw line longer than MaxRawSize: %d", len(fe.Raw))
+			}
+		}
+	})
+}
+
+func FuzzHeaderResolution(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(strings.SplitN(seed, "\n", 2)[0])
+	}
+	f.Add("Field 0,field_1,FIELD-2,OLD_3")
+	f.Add("CH1,CH2,CH3,CH4,SENSOR")
+	f.Fuzz(func(t *testing.T, header string) {
+		opts := []Option{WithFuzzyHeaderMatch(), WithHeaderAliases(map[string]string{"OLD_3": "FIELD_3"})}
+		for _, s := range []interface{}{TestStruct{}, ChannelStruct{}} {
+			m, err := NewMarshaler(s, strings.NewReader(header+"\n"), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.Lazy = true
+			if _, err := m.Unmarshal(); err != nil && !documentedError(err) && !errors.Is(err, ErrHeaderNotComplete) {
+				t.Fatalf("undocumented error %T: %v", err, err)
+			}
+		}
+	})
+}
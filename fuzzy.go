@@ -0,0 +1,104 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrAmbiguousHeader  = errors.New("ambiguous fuzzy header match")
+	ErrFuzzyHeaderMatch = errors.New("fuzzy header match")
+)
+
+// WithFuzzyHeaderMatch matches columns without exact csv tag name case-insensitive and, if that
+// fails, with spaces, underscores and dashes removed, e.g. the column "Field 0" binds to the tag
+// FIELD_0. Columns matched that way are listed in the Report as FuzzyMatches and recorded as
+// ErrFuzzyHeaderMatch warning. A column matching several tags or a tag matching several columns
+// is an ErrAmbiguousHeader.
+func WithFuzzyHeaderMatch() Option {
+	return func(m *Marshaler) {
+		m.fuzzy = true
+	}
+}
+
+// fuzzyKey returns name case folded without spaces, underscores and dashes.
+func fuzzyKey(name string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '_' || r == '-' {
+			return -1
+		}
+		return r
+	}, name))
+}
+
+// fuzzyHeader renames the columns of record that match a csv tag name of WithFuzzyHeaderMatch
+// to the tag name. Columns matching a tag name exactly are kept.
+func (m *Marshaler) fuzzyHeader(record stringSlice) (stringSlice, error) {
+	if !m.fuzzy {
+		return record, nil
+	}
+	known := map[string]bool{} // csv tag names
+	tags := []string{}
+	for _, fieldInfo := range m.fieldInfos {
+		names := []string{fieldInfo.headerName}
+		if fieldInfo.isComposite() {
+			names = fieldInfo.parts
+		} else if fieldInfo.isGlob() {
+			continue
+		}
+		for _, name := range names {
+			if !known[name] {
+				known[name] = true
+				tags = append(tags, name)
+			}
+		}
+	}
+	matched := map[string]bool{} // csv tag names with column
+	for _, column := range record {
+		matched[column] = true
+	}
+	for _, key := range []func(string) string{strings.ToLower, fuzzyKey} {
+		names := map[string][]string{} // csv tag names without column by key
+		for _, name := range tags {
+			if !matched[name] {
+				names[key(name)] = append(names[key(name)], name)
+			}
+		}
+		for i, column := range record {
+			if known[column] {
+				continue
+			}
+			candidates := names[key(column)]
+			if len(candidates) > 1 {
+				return nil, &csv.ParseError{Line: 1, Column: i, Err: fmt.Errorf("%w: column %s matches %s", ErrAmbiguousHeader,
+					column, strings.Join(candidates, " and "))}
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+			for j, other := range record[i+1:] {
+				if !known[other] && key(other) == key(column) {
+					return nil, &csv.ParseError{Line: 1, Column: i + 1 + j, Err: fmt.Errorf("%w: columns %s and %s match %s",
+						ErrAmbiguousHeader, column, other, candidates[0])}
+				}
+			}
+			if m.report.FuzzyMatches == nil {
+				m.report.FuzzyMatches = map[string]string{}
+			}
+			m.report.FuzzyMatches[column] = candidates[0]
+			if pe := m.warn(csv.ParseError{StartLine: m.headerLine, Line: m.headerLine, Column: i, Err: &FieldError{
+				Header: candidates[0],
+				Value:  column,
+				Err:    fmt.Errorf("%w: column %s bound to %s", ErrFuzzyHeaderMatch, column, candidates[0]),
+			}}); pe != nil {
+				return nil, pe
+			}
+			record[i] = candidates[0]
+			matched[candidates[0]] = true
+			delete(names, key(column))
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,69 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyHeaderMatch(t *testing.T) {
+	tt := map[string]struct {
+		header  string
+		fuzzy   map[string]string
+		wantErr error
+	}{
+		"exact":            {header: "FIELD_0,FIELD_1,FIELD_2,FIELD_3"},
+		"case":             {header: "field_0,FIELD_1,Field_2,FIELD_3", fuzzy: map[string]string{"field_0": "FIELD_0", "Field_2": "FIELD_2"}},
+		"separators":       {header: "Field 0,field-1,FIELD2,FIELD_3", fuzzy: map[string]string{"Field 0": "FIELD_0", "field-1": "FIELD_1", "FIELD2": "FIELD_2"}},
+		"case before fuzz": {header: "Field 0,field_0,FIELD_1,FIELD_2,FIELD_3", fuzzy: map[string]string{"field_0": "FIELD_0"}},
+		"exact before":     {header: "field_0,FIELD_0,FIELD_1,FIELD_2,FIELD_3"},
+		"ambiguous":        {header: "Field 0,field-0,FIELD_1,FIELD_2,FIELD_3", wantErr: ErrAmbiguousHeader},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			data := tc.header + "\n" + strings.Repeat("a,", strings.Count(tc.header, ",")-3) + "a,1,true,1.5\n"
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithFuzzyHeaderMatch())
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.Unmarshal()
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("wrong error - want: %s, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []interface{}{TestStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5}}; !reflect.DeepEqual(result, want) {
+				t.Errorf("wrong result - want: %v, got: %v", want, result)
+			}
+			if got := m.Report().FuzzyMatches; !reflect.DeepEqual(got, tc.fuzzy) {
+				t.Errorf("wrong fuzzy matches - want: %v, got: %v", tc.fuzzy, got)
+			}
+		})
+	}
+
+	type Collapsing struct {
+		ABC  string `csv:"AB_C"`
+		ABC2 string `csv:"A_BC"`
+	}
+	m, err := NewMarshaler(Collapsing{}, strings.NewReader("abc,x\na,b\n"), WithFuzzyHeaderMatch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); !errors.Is(err, ErrAmbiguousHeader) || !strings.Contains(err.Error(), "AB_C and A_BC") {
+		t.Errorf("wrong error for tags with the same key - want: %s listing both tags, got: %v", ErrAmbiguousHeader, err)
+	}
+
+	// without the option the header is incomplete
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader("Field 0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); !errors.Is(err, ErrHeaderNotComplete) {
+		t.Errorf("wrong error without fuzzy match - want: %s, got: %v", ErrHeaderNotComplete, err)
+	}
+}
@@ -0,0 +1,164 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrArrayLength = errors.New("number of columns does not match array length")
+)
+
+// Fields with the glob tag option bind to all columns matching the header name pattern,
+// which contains exactly one *, e.g. `csv:"TEMP_*,glob"`. Map fields with string keys are
+// keyed by the part of the header matched by the *, slice fields require the ordered option
+// and contain the values ordered by the matched part, numerically if all parts are integers.
+// Array fields are ordered like slices, e.g. `csv:"CH*,glob,ordered"` for a [4]float64, and
+// require exactly one column per element.
+
+// isGlob checks if the field binds to multiple columns.
+func (fieldInfo fieldInfo) isGlob() bool {
+	_, ok := fieldInfo.options["glob"]
+	return ok || fieldInfo.isGroup()
+}
+
+// validateGlob checks the header name pattern and type of a glob field.
+func validateGlob(headerName string, typ reflect.Type, options tagOptions) error {
+	if strings.Count(headerName, "*") != 1 {
+		return fmt.Errorf("glob pattern %s must contain exactly one *", headerName)
+	}
+	_, ordered := options["ordered"]
+	switch {
+	case typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String:
+		return nil
+	case (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && ordered:
+		return nil
+	case typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array:
+		return fmt.Errorf("glob %s field requires the ordered option", typ.Kind())
+	}
+	return fmt.Errorf("glob field must be a map with string keys, a slice or an array, got: %s", typ)
+}
+
+// globMatch returns the part of name matched by the * of pattern.
+func globMatch(pattern, name string) (string, bool) {
+	i := strings.Index(pattern, "*")
+	prefix, suffix := pattern[:i], pattern[i+1:]
+	if len(name) < len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	return name[len(prefix) : len(name)-len(suffix)], true
+}
+
+// resolveGlob sets the positions and keys of the columns matching the glob pattern, see
+// headerMatcher.match.
+func (fieldInfo *fieldInfo) resolveGlob(columns []patternColumn) {
+	fieldInfo.positions, fieldInfo.keys = nil, nil
+	for _, column := range columns {
+		fieldInfo.positions = append(fieldInfo.positions, column.position)
+		fieldInfo.keys = append(fieldInfo.keys, column.key)
+	}
+	if _, ok := fieldInfo.options["ordered"]; ok {
+		sort.Sort(globColumns{fieldInfo, numericKeys(fieldInfo.keys)})
+	}
+}
+
+// checkArrayLength checks that a glob array field matches one column per element.
+func (fieldInfo fieldInfo) checkArrayLength() error {
+	if !fieldInfo.isGlob() || fieldInfo.kind != reflect.Array || len(fieldInfo.positions) == fieldInfo.typ.Len() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s matches %d columns, field %s has %d elements", ErrArrayLength,
+		fieldInfo.headerName, len(fieldInfo.positions), fieldInfo.fieldName, fieldInfo.typ.Len())
+}
+
+// decodeGlob converts the cells of all matching columns to a map or slice.
+func (m *Marshaler) decodeGlob(fieldInfo fieldInfo, record []string, line int) (interface{}, *csv.ParseError) {
+	elem := fieldInfo
+	elem.typ = fieldInfo.typ.Elem()
+	elem.kind = elem.typ.Kind()
+	var v reflect.Value
+	switch fieldInfo.kind {
+	case reflect.Map:
+		v = reflect.MakeMapWithSize(fieldInfo.typ, len(fieldInfo.positions))
+	case reflect.Array:
+		v = reflect.New(fieldInfo.typ).Elem()
+	default:
+		v = reflect.MakeSlice(fieldInfo.typ, 0, len(fieldInfo.positions))
+	}
+	for i, position := range fieldInfo.positions {
+		cell, err := m.cell(fieldInfo, record, position)
+		if err == nil {
+			err = fieldInfo.matchCell(cell)
+		}
+		var value interface{}
+		if err == nil {
+			value, err = m.convert(elem, cell)
+		}
+		// values of registered converters are not checked by convert
+		if err == nil && (value == nil || !reflect.TypeOf(value).AssignableTo(elem.typ)) {
+			err = fmt.Errorf("value of type %T not assignable to %s", value, elem.typ)
+		}
+		if err == nil && fieldInfo.enum != nil {
+			err = fieldInfo.enum.check(value)
+		}
+		if err == nil {
+			err = fieldInfo.checkBounds(value)
+		}
+		if err != nil {
+			return nil, &csv.ParseError{Column: position, Line: line, Err: &FieldError{
+				Field:  fieldInfo.fieldName,
+				Header: m.header[position],
+				Value:  cell,
+				Err:    err,
+			}}
+		}
+		switch fieldInfo.kind {
+		case reflect.Map:
+			v.SetMapIndex(reflect.ValueOf(fieldInfo.keys[i]).Convert(fieldInfo.typ.Key()), reflect.ValueOf(value))
+		case reflect.Array:
+			v.Index(i).Set(reflect.ValueOf(value))
+		default:
+			v = reflect.Append(v, reflect.ValueOf(value))
+		}
+	}
+	return v.Interface(), nil
+}
+
+// numericKeys checks if all keys are integers.
+func numericKeys(keys []string) bool {
+	for _, key := range keys {
+		if _, err := strconv.Atoi(key); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// globColumns sorts the positions and keys of a glob field by key.
+type globColumns struct {
+	*fieldInfo
+	numeric bool
+}
+
+func (g globColumns) Len() int {
+	return len(g.keys)
+}
+
+func (g globColumns) Less(i, j int) bool {
+	if g.numeric {
+		a, _ := strconv.Atoi(g.keys[i])
+		b, _ := strconv.Atoi(g.keys[j])
+		return a < b
+	}
+	return g.keys[i] < g.keys[j]
+}
+
+func (g globColumns) Swap(i, j int) {
+	g.keys[i], g.keys[j] = g.keys[j], g.keys[i]
+	g.positions[i], g.positions[j] = g.positions[j], g.positions[i]
+}
@@ -0,0 +1,153 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type SensorStruct struct {
+	Sensor  string             `csv:"SENSOR"`
+	Temps   map[string]float64 `csv:"TEMP_*,glob"`
+	Humids  []float64          `csv:"HUMID_*_PCT,glob,ordered"`
+	Options []string           `csv:"OPT_*,glob,ordered"`
+}
+
+func TestUnmarshalGlob(t *testing.T) {
+	data := `SENSOR;TEMP_1;HUMID_10_PCT;TEMP_2;HUMID_2_PCT;HUMID_1_PCT;TEMP_12;OPT_b;OPT_a
+s1;1.5;10;2.5;2;1;12.5;b;a
+s2;1;2;3;4;5;6;;`
+	m, err := NewMarshaler(SensorStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatalf("error in UnmarshalCSV: %s", err)
+	}
+	want := []interface{}{
+		SensorStruct{
+			Sensor:  "s1",
+			Temps:   map[string]float64{"1": 1.5, "2": 2.5, "12": 12.5},
+			Humids:  []float64{1, 2, 10},
+			Options: []string{"a", "b"},
+		},
+		SensorStruct{
+			Sensor:  "s2",
+			Temps:   map[string]float64{"1": 1, "2": 3, "12": 6},
+			Humids:  []float64{5, 4, 2},
+			Options: []string{"", ""},
+		},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+}
+
+func TestUnmarshalGlobMissingGroups(t *testing.T) {
+	data := `SENSOR;TEMP_3
+s1;3.5
+s2;invalid`
+	m, err := NewMarshaler(SensorStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	want := []interface{}{
+		SensorStruct{Sensor: "s1", Temps: map[string]float64{"3": 3.5}, Humids: []float64{}, Options: []string{}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 1 || pe[0].Column != 1 {
+		t.Fatalf("wrong error - want ParseError in column 1, got: %v", err)
+	}
+	if fe, ok := pe[0].Err.(*FieldError); !ok || fe.Header != "TEMP_3" || fe.Field != "Temps" {
+		t.Errorf("wrong field error: %v", pe[0].Err)
+	}
+}
+
+type ChannelStruct struct {
+	Sensor   string     `csv:"SENSOR"`
+	Channels [4]float64 `csv:"CH*,glob,ordered"`
+}
+
+func TestUnmarshalGlobArray(t *testing.T) {
+	m, err := NewMarshaler(ChannelStruct{}, strings.NewReader("CH3,SENSOR,CH1,CH2,CH4\n3,s1,1,2,4\n3,s2,1,x,4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	want := []interface{}{ChannelStruct{Sensor: "s1", Channels: [4]float64{1, 2, 3, 4}}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 1 || pe[0].Column != 3 {
+		t.Fatalf("wrong error - want ParseError in column 3, got: %v", err)
+	}
+	if fe, ok := pe[0].Err.(*FieldError); !ok || fe.Header != "CH2" || fe.Field != "Channels" {
+		t.Errorf("wrong field error: %v", pe[0].Err)
+	}
+
+	for _, header := range []string{"SENSOR,CH1,CH2,CH3", "SENSOR,CH1,CH2,CH3,CH4,CH5"} {
+		m, err := NewMarshaler(ChannelStruct{}, strings.NewReader(header+"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Unmarshal(); !errors.Is(err, ErrArrayLength) {
+			t.Errorf("wrong error for header %s - want: %s, got: %v", header, ErrArrayLength, err)
+		}
+	}
+}
+
+func TestCsvHeadersInvalidGlob(t *testing.T) {
+	type NoWildcard struct {
+		Temps map[string]float64 `csv:"TEMP,glob"`
+	}
+	type TwoWildcards struct {
+		Temps map[string]float64 `csv:"TEMP_*_*,glob"`
+	}
+	type UnorderedSlice struct {
+		Temps []float64 `csv:"TEMP_*,glob"`
+	}
+	type IntKeys struct {
+		Temps map[int]float64 `csv:"TEMP_*,glob"`
+	}
+	type NoCollection struct {
+		Temp float64 `csv:"TEMP_*,glob"`
+	}
+	type UnorderedArray struct {
+		Temps [2]float64 `csv:"TEMP_*,glob"`
+	}
+	for _, invalid := range []interface{}{NoWildcard{}, TwoWildcards{}, UnorderedSlice{}, IntKeys{}, NoCollection{}, UnorderedArray{}} {
+		if _, err := createFieldInfos(invalid); err == nil {
+			t.Errorf("no error for invalid glob field %T, but it should", invalid)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	var globMatchTests = []struct {
+		pattern, name, key string
+		ok                 bool
+	}{
+		{"TEMP_*", "TEMP_1", "1", true},
+		{"TEMP_*", "TEMP_", "", true},
+		{"TEMP_*", "TEMP", "", false},
+		{"*_PCT", "HUMID_PCT", "HUMID", true},
+		{"A*A", "A", "", false},
+		{"A*A", "AA", "", true},
+		{"TEMP_*", "OTHER_1", "", false},
+	}
+	for _, test := range globMatchTests {
+		key, ok := globMatch(test.pattern, test.name)
+		if key != test.key || ok != test.ok {
+			t.Errorf("wrong match of %s with %s - want: %q %t, got: %q %t", test.name, test.pattern, test.key, test.ok, key, ok)
+		}
+	}
+}
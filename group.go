@@ -0,0 +1,163 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fields with the group tag option bind to repeated groups of numbered columns, e.g.
+// `csv:"MODIFIED_*,group"` for a []Audit field, where Audit has the csv tags BY and AT, binds to
+// MODIFIED_BY_1, MODIFIED_AT_1, MODIFIED_BY_2 and so on. The * matches the csv tag of an element
+// field followed by an optional underscore and the index. The slice contains one element per index
+// of the header ordered by index, gaps in the indexes are left out. Element fields without column
+// and with empty cells keep their zero value.
+
+// isGroup checks if the field binds to repeated groups of columns, see the group option. Group
+// fields are glob fields as well.
+func (fieldInfo fieldInfo) isGroup() bool {
+	_, ok := fieldInfo.options["group"]
+	return ok
+}
+
+// parseGroup returns the fields of the element struct of a group field, nil without group option.
+func parseGroup(headerName string, typ reflect.Type, options tagOptions) (fieldInfos, error) {
+	if _, ok := options["group"]; !ok {
+		return nil, nil
+	}
+	if strings.Count(headerName, "*") != 1 {
+		return nil, fmt.Errorf("group pattern %s must contain exactly one *", headerName)
+	}
+	for _, option := range []string{"glob", "ordered"} {
+		if _, ok := options[option]; ok {
+			return nil, fmt.Errorf("option group cannot be combined with option %s", option)
+		}
+	}
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("option group requires a slice of structs, got: %s", typ)
+	}
+	group, err := createFieldInfos(reflect.New(typ.Elem()).Elem().Interface())
+	if err != nil {
+		return nil, fmt.Errorf("group element %s: %s", typ.Elem(), err)
+	}
+	if len(group) == 0 {
+		return nil, fmt.Errorf("group element %s has no csv fields", typ.Elem())
+	}
+	for _, member := range group {
+		if member.isComposite() || member.isGlob() || member.cols != nil {
+			return nil, fmt.Errorf("group element field %s must bind to a single column", member.fieldName)
+		}
+		if _, ok := member.pinned(); ok {
+			return nil, fmt.Errorf("group element field %s cannot have the pos option", member.fieldName)
+		}
+	}
+	return group, nil
+}
+
+// groupMatch returns the index of the element field and the group index of the column name.
+func (fieldInfo fieldInfo) groupMatch(name string) (int, int, bool) {
+	part, ok := globMatch(fieldInfo.headerName, name)
+	if !ok {
+		return 0, 0, false
+	}
+	tag := strings.TrimRight(part, "0123456789")
+	index, err := strconv.Atoi(part[len(tag):])
+	if err != nil {
+		return 0, 0, false
+	}
+	tag = strings.TrimSuffix(tag, "_")
+	for i, member := range fieldInfo.group {
+		if member.headerName == tag {
+			return i, index, true
+		}
+	}
+	return 0, 0, false
+}
+
+// resolveGroup sets the columns of the element fields for every group index of the matched
+// columns, see headerMatcher.match. Element fields without column have the position -1. The first
+// column of an element field wins.
+func (fieldInfo *fieldInfo) resolveGroup(matched []patternColumn) {
+	columns := map[int][]int{} // positions of the element fields by group index
+	for _, column := range matched {
+		positions, ok := columns[column.index]
+		if !ok {
+			positions = make([]int, len(fieldInfo.group))
+			for j := range positions {
+				positions[j] = -1
+			}
+			columns[column.index] = positions
+		}
+		if positions[column.member] < 0 {
+			positions[column.member] = column.position
+		}
+	}
+	indexes := make([]int, 0, len(columns))
+	for index := range columns {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	fieldInfo.groups = make([][]int, 0, len(indexes))
+	for _, index := range indexes {
+		fieldInfo.groups = append(fieldInfo.groups, columns[index])
+	}
+}
+
+// decodeGroup converts the cells of every group of columns to an element struct of the slice.
+func (m *Marshaler) decodeGroup(fieldInfo fieldInfo, record []string, line int) (interface{}, *csv.ParseError) {
+	v := reflect.MakeSlice(fieldInfo.typ, 0, len(fieldInfo.groups))
+	for _, positions := range fieldInfo.groups {
+		elem := reflect.New(fieldInfo.typ.Elem()).Elem()
+		for j, position := range positions {
+			if position < 0 {
+				continue
+			}
+			member := fieldInfo.group[j]
+			cell, err := m.cell(member, record, position)
+			if err == nil && len(cell) == 0 {
+				continue
+			}
+			if err == nil {
+				err = member.matchCell(cell)
+			}
+			var value interface{}
+			if err == nil {
+				value, err = m.convert(member, cell)
+			}
+			// values of registered converters are not checked by convert
+			if err == nil && (value == nil || !reflect.TypeOf(value).AssignableTo(member.typ)) {
+				err = fmt.Errorf("value of type %T not assignable to %s", value, member.typ)
+			}
+			if err == nil && member.enum != nil {
+				err = member.enum.check(value)
+			}
+			if err == nil {
+				err = member.checkBounds(value)
+			}
+			if err != nil {
+				return nil, &csv.ParseError{Column: position, Line: line, Err: &FieldError{
+					Field:  fieldInfo.fieldName + "." + member.fieldName,
+					Header: m.header[position],
+					Value:  cell,
+					Err:    err,
+				}}
+			}
+			elem.FieldByName(member.fieldName).Set(reflect.ValueOf(value))
+		}
+		v = reflect.Append(v, elem)
+	}
+	return v.Interface(), nil
+}
+
+// checkGroupDecodable checks that convert supports the element fields of a group field.
+func (m *Marshaler) checkGroupDecodable(fieldInfo fieldInfo) error {
+	for _, member := range fieldInfo.group {
+		if !m.decodable(member.typ) {
+			return fmt.Errorf("%w: field %s.%s of type %s", ErrUnsupportedCSVType, fieldInfo.fieldName, member.fieldName, member.typ)
+		}
+	}
+	return nil
+}
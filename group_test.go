@@ -0,0 +1,136 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Audit struct {
+	By string    `csv:"BY"`
+	At time.Time `csv:"AT,format=2006-01-02"`
+}
+
+type AuditedStruct struct {
+	ID     int     `csv:"ID"`
+	Audits []Audit `csv:"MODIFIED_*,group"`
+}
+
+func TestUnmarshalGroup(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	tt := map[string]struct {
+		data string
+		want []interface{}
+	}{
+		"two groups": {
+			data: "ID,MODIFIED_BY_1,MODIFIED_AT_1,MODIFIED_BY_2,MODIFIED_AT_2\n1,anna,2024-01-02,ben,2024-03-04\n",
+			want: []interface{}{AuditedStruct{ID: 1, Audits: []Audit{{By: "anna", At: date("2024-01-02")}, {By: "ben", At: date("2024-03-04")}}}},
+		},
+		"ordered by index": {
+			data: "MODIFIED_AT_10,MODIFIED_BY_10,ID,MODIFIED_BY_2,MODIFIED_AT_2\n2024-03-04,ben,1,anna,2024-01-02\n",
+			want: []interface{}{AuditedStruct{ID: 1, Audits: []Audit{{By: "anna", At: date("2024-01-02")}, {By: "ben", At: date("2024-03-04")}}}},
+		},
+		"index gap": {
+			data: "ID,MODIFIED_BY_1,MODIFIED_BY_3\n1,anna,ben\n",
+			want: []interface{}{AuditedStruct{ID: 1, Audits: []Audit{{By: "anna"}, {By: "ben"}}}},
+		},
+		"without underscore": {
+			data: "ID,MODIFIED_BY1,MODIFIED_AT1\n1,anna,2024-01-02\n",
+			want: []interface{}{AuditedStruct{ID: 1, Audits: []Audit{{By: "anna", At: date("2024-01-02")}}}},
+		},
+		"empty and missing cells": {
+			data: "ID,MODIFIED_BY_1,MODIFIED_AT_1,MODIFIED_BY_2,MODIFIED_AT_2\n1,anna,,,\n2,ben\n",
+			want: []interface{}{
+				AuditedStruct{ID: 1, Audits: []Audit{{By: "anna"}, {}}},
+				AuditedStruct{ID: 2, Audits: []Audit{{By: "ben"}, {}}},
+			},
+		},
+		"no group": {
+			data: "ID\n1\n",
+			want: []interface{}{AuditedStruct{ID: 1, Audits: []Audit{}}},
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(AuditedStruct{}, strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.Reader.FieldsPerRecord = -1
+			result, err := m.Unmarshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tc.want) {
+				t.Errorf("wrong result - want: %v, got: %v", tc.want, result)
+			}
+		})
+	}
+}
+
+func TestUnmarshalGroupErrors(t *testing.T) {
+	m, err := NewMarshaler(AuditedStruct{}, strings.NewReader("ID,MODIFIED_BY_1,MODIFIED_AT_1\n1,anna,02.01.2024\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Unmarshal()
+	var errs ParseErrors
+	var fe *FieldError
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Column != 2 || !errors.As(errs[0].Err, &fe) || fe.Field != "Audits.At" {
+		t.Errorf("wrong error - want: error of Audits.At in column 2, got: %v", err)
+	}
+
+	// columns of the pattern without element field are extra
+	schema, err := NewSchema(AuditedStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var herr *HeaderError
+	err = schema.ValidateHeader([]string{"ID", "MODIFIED_BY_1", "MODIFIED_ON_1", "MODIFIED_BY"})
+	if !errors.As(err, &herr) || !reflect.DeepEqual(herr.Extra, []string{"MODIFIED_ON_1", "MODIFIED_BY"}) {
+		t.Errorf("wrong error - want: extra columns MODIFIED_ON_1 and MODIFIED_BY, got: %v", err)
+	}
+
+	tags := map[string]interface{}{
+		"not a slice": struct {
+			F Audit `csv:"F_*,group"`
+		}{},
+		"no pattern": struct {
+			F []Audit `csv:"F,group"`
+		}{},
+		"glob": struct {
+			F []Audit `csv:"F_*,group,glob"`
+		}{},
+		"nested group": struct {
+			F []AuditedStruct `csv:"F_*,group"`
+		}{},
+		"pinned member": struct {
+			F []struct {
+				By string `csv:"BY,pos=1"`
+			} `csv:"F_*,group"`
+		}{},
+	}
+	for name, s := range tags {
+		if _, err := NewSchema(s); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+
+	type Undecodable struct {
+		C chan int `csv:"C"`
+	}
+	m, err = NewMarshaler(struct {
+		F []Undecodable `csv:"F_*,group"`
+	}{}, strings.NewReader("F_C_1\n1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); !errors.Is(err, ErrUnsupportedCSVType) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrUnsupportedCSVType, err)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build go1.23
+
+package csv
+
+import (
+	"iter"
+	"reflect"
+)
+
+// All returns an iterator over the records of the Reader built on Next. It yields every decoded
+// record with a nil error and every error of a single record with a nil record, then continues with
+// the next record; with Lazy these errors are yielded once as ParseErrors at the end instead. The
+// iteration stops at the end of the input or after yielding a fatal error. If the caller breaks
+// early, the remaining records can be read with Next or another call to All.
+func (m *Marshaler) All() iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		for {
+			if m.Next() {
+				if !yield(m.Record(), nil) {
+					return
+				}
+				continue
+			}
+			err := m.Err()
+			if err == nil {
+				return
+			}
+			if m.Skip() != nil { // fatal error or ParseErrors at the end of the input
+				yield(nil, err)
+				return
+			}
+			if !yield(nil, err) {
+				return
+			}
+		}
+	}
+}
+
+// Records returns an iterator like Marshaler.All that yields the records as T, which has to be
+// the type of the endpoint struct of m, otherwise ErrWrongStructType is yielded.
+func Records[T any](m *Marshaler) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if reflect.TypeOf(m.endPointStruct) != reflect.TypeOf(&zero).Elem() {
+			yield(zero, ErrWrongStructType)
+			return
+		}
+		for record, err := range m.All() {
+			t, _ := record.(T)
+			if !yield(t, err) {
+				return
+			}
+		}
+	}
+}
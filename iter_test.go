@@ -0,0 +1,96 @@
+//go:build go1.23
+
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ExampleMarshaler_All() {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,x,true,2.5\nc,3,false,3.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	for record, err := range m.All() {
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		fmt.Println(record.(TestStruct).Field0)
+	}
+	// Output:
+	// a
+	// error: record on line 0; parse error on line 3, column 1: field:Field1,header:FIELD_1,err:strconv.ParseInt: parsing "x": invalid syntax
+	// c
+}
+
+func ExampleRecords() {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,true,2.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	for record, err := range Records[TestStruct](m) {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(record.Field0, record.Field1)
+	}
+	// Output:
+	// a 1
+	// b 2
+}
+
+func TestAll(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,x,true,2.5\nc,3,false,3.5\n"
+
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	names, errs := []string{}, 0
+	for record, err := range Records[TestStruct](m) {
+		if err != nil {
+			var pe ParseErrors
+			if !errors.As(err, &pe) || len(pe) != 1 {
+				t.Errorf("wrong error with Lazy - want: 1 ParseError, got: %v", err)
+			}
+			errs++
+			continue
+		}
+		names = append(names, record.Field0)
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(names, want) || errs != 1 {
+		t.Errorf("wrong records - want: %v and 1 error, got: %v and %d", want, names, errs)
+	}
+
+	// breaking early leaves the remaining records to Next
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range m.All() {
+		break
+	}
+	names = []string{}
+	for record, err := range m.All() {
+		if err == nil {
+			names = append(names, record.(TestStruct).Field0)
+		}
+	}
+	if want := []string{"c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("wrong records after break - want: %v, got: %v", want, names)
+	}
+
+	for _, err := range Records[NormalizeStruct](m) {
+		if err != ErrWrongStructType {
+			t.Errorf("wrong error - want: %s, got: %v", ErrWrongStructType, err)
+		}
+	}
+}
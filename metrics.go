@@ -0,0 +1,48 @@
+package csv
+
+import (
+	"encoding/csv"
+	"time"
+)
+
+// Metrics is notified about the progress of Unmarshal and Next, e.g. to export counters
+// to a monitoring system. The duration of Next is observed once at the end of the input or
+// on an error that cannot be skipped. Preview is not observed.
+type Metrics interface {
+	ObserveRow(ok bool)              // called for every data row, ok is false if the row failed
+	ObserveBytes(n int64)            // called with the number of bytes read for every line
+	ObserveDuration(d time.Duration) // called with the duration of a parse
+}
+
+// noopMetrics is the default Metrics of a Marshaler.
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRow(bool)               {}
+func (noopMetrics) ObserveBytes(int64)            {}
+func (noopMetrics) ObserveDuration(time.Duration) {}
+
+// observeBytes reports the bytes read by r since the last call.
+func (m *Marshaler) observeBytes(r *csv.Reader) {
+	offset := m.inputOffset(r)
+	if offset > m.offset {
+		m.Metrics.ObserveBytes(offset - m.offset)
+	}
+	m.offset = offset
+}
+
+// inputOffset returns the input offset of r without the bytes replayed by a resync.
+func (m *Marshaler) inputOffset(r *csv.Reader) int64 {
+	if r == m.Reader && m.lines != nil {
+		return r.InputOffset() - m.lines.bytes
+	}
+	return r.InputOffset()
+}
+
+// InputOffset returns the number of bytes of the input consumed by the records read so far, the
+// header included. A file can be resumed after the last record at this offset, e.g. with an HTTP
+// Range request. The offset counts the bytes of the io.Reader passed to the Marshaler, if it
+// decodes its source, e.g. a gzip.Reader, they are the decoded bytes and not those of the source.
+// Lines replayed with Lazy are counted once.
+func (m *Marshaler) InputOffset() int64 {
+	return m.inputOffset(m.Reader)
+}
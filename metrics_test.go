@@ -0,0 +1,119 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type countingMetrics struct {
+	ok, failed int
+	bytes      int64
+	durations  []time.Duration
+}
+
+func (c *countingMetrics) ObserveRow(ok bool) {
+	if ok {
+		c.ok++
+	} else {
+		c.failed++
+	}
+}
+
+func (c *countingMetrics) ObserveBytes(n int64) {
+	c.bytes += n
+}
+
+func (c *countingMetrics) ObserveDuration(d time.Duration) {
+	c.durations = append(c.durations, d)
+}
+
+func TestMetrics(t *testing.T) {
+	// wrongTypes has one decode error, tooManyFields two field count errors
+	var metricsTests = map[string]struct {
+		data         string
+		lazy, stream bool
+		ok, failed   int
+	}{
+		"unmarshal":      {wrongTypes, false, false, 2, 1},
+		"unmarshal lazy": {tooManyFields, true, false, 1, 2},
+		"stream":         {wrongTypes, true, true, 2, 1},
+	}
+	for name, test := range metricsTests {
+		metrics := &countingMetrics{}
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(test.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		m.Lazy = test.lazy
+		m.Metrics = metrics
+		if _, _, _, err := m.Preview(1); err != nil {
+			t.Fatal(err)
+		}
+		if test.stream {
+			for m.Next() {
+			}
+			m.Next()
+		} else {
+			m.Unmarshal()
+		}
+		if metrics.ok != test.ok || metrics.failed != test.failed {
+			t.Errorf("wrong rows for test '%s' - want: %d ok and %d failed, got: %d and %d", name, test.ok, test.failed, metrics.ok, metrics.failed)
+		}
+		if metrics.bytes != int64(len(test.data)) {
+			t.Errorf("wrong bytes for test '%s' - want: %d, got: %d", name, len(test.data), metrics.bytes)
+		}
+		if len(metrics.durations) != 1 {
+			t.Errorf("wrong number of durations for test '%s' - want: %d, got: %d", name, 1, len(metrics.durations))
+		}
+	}
+}
+
+func TestInputOffset(t *testing.T) {
+	tt := map[string]struct {
+		records []string // header and records with their line endings
+		opts    []Option
+	}{
+		"plain":      {records: []string{"FIELD_0,FIELD_1,FIELD_2,FIELD_3\n", "a,1,true,1.5\n", "b,2,true,2.5\n"}},
+		"crlf":       {records: []string{"FIELD_0,FIELD_1,FIELD_2,FIELD_3\r\n", "a,1,true,1.5\r\n", "b,2,true,2.5\r\n"}},
+		"comments":   {records: []string{"# c\nFIELD_0,FIELD_1,FIELD_2,FIELD_3\n", "# c\na,1,true,1.5\n", "\nb,2,true,2.5\n"}, opts: []Option{WithComment('#')}},
+		"multi-line": {records: []string{"FIELD_0,FIELD_1,FIELD_2,FIELD_3\n", "\"a\nb\",1,true,1.5\n", "c,2,true,2.5"}},
+		"delimiter":  {records: []string{"FIELD_0||FIELD_1||FIELD_2||FIELD_3\n", "a,b||1||true||1.5\n", "c||2||true||2.5\n"}, opts: []Option{WithDelimiter("||")}},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(strings.Join(tc.records, "")), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.InputOffset() != 0 {
+				t.Errorf("wrong offset before reading - want: 0, got: %d", m.InputOffset())
+			}
+			want := int64(len(tc.records[0]))
+			for i, record := range tc.records[1:] {
+				want += int64(len(record))
+				if !m.Next() {
+					t.Fatalf("no record %d: %v", i, m.Err())
+				}
+				if m.InputOffset() != want {
+					t.Errorf("wrong offset after record %d - want: %d, got: %d", i, want, m.InputOffset())
+				}
+			}
+		})
+	}
+
+	// failed rows and the lines replayed after a broken quote are counted once
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,x,true,1.5\n\"b,2,true,2.5\nc,3,true,3.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	if _, err := m.Unmarshal(); err == nil {
+		t.Fatal("no error for failed rows, but it should")
+	}
+	if m.InputOffset() != int64(len(data)) {
+		t.Errorf("wrong offset after failed rows - want: %d, got: %d", len(data), m.InputOffset())
+	}
+}
@@ -0,0 +1,106 @@
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// The tag options upper, lower and title normalize the case of string cells before they are
+// converted, e.g. `csv:"COUNTRY,upper"`. Fields of types implementing encoding.TextUnmarshaler
+// require the additional option normalizetext, e.g. `csv:"COUNTRY,upper,normalizetext"`.
+
+// normalizations are the tag options that normalize a cell.
+var normalizations = map[string]func(string) string{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": title,
+}
+
+// validateNormalization checks that a field has at most one normalization option and is a
+// string or an encoding.TextUnmarshaler with the normalizetext option, typ is the element type
+// for glob fields.
+func validateNormalization(typ reflect.Type, options tagOptions) error {
+	found := []string{}
+	for option := range normalizations {
+		if _, ok := options[option]; ok {
+			found = append(found, option)
+		}
+	}
+	if len(found) > 1 {
+		return fmt.Errorf("only one of the options upper, lower and title is allowed")
+	}
+	_, text := options["normalizetext"]
+	if len(found) == 0 {
+		if text {
+			return fmt.Errorf("option normalizetext requires one of the options upper, lower or title")
+		}
+		return nil
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		if !text {
+			return fmt.Errorf("option %s requires the option normalizetext for encoding.TextUnmarshaler fields, got: %s", found[0], typ)
+		}
+		return nil
+	}
+	if typ.Kind() != reflect.String {
+		return fmt.Errorf("option %s requires a string field, got: %s", found[0], typ)
+	}
+	return nil
+}
+
+// validateRaw checks that the raw option is only used for string fields and without options that
+// change the cell, typ is the element type for glob fields.
+func validateRaw(typ reflect.Type, options tagOptions) error {
+	if _, ok := options["raw"]; !ok {
+		return nil
+	}
+	if typ.Kind() != reflect.String {
+		return fmt.Errorf("option raw requires a string field, got: %s", typ)
+	}
+	for _, option := range []string{"upper", "lower", "title", "normalizetext", "unquote", "default"} {
+		if _, ok := options[option]; ok {
+			return fmt.Errorf("option raw cannot be combined with option %s", option)
+		}
+	}
+	return nil
+}
+
+// isRaw checks if the cells of the field are kept byte for byte, see the raw option.
+func (fieldInfo fieldInfo) isRaw() bool {
+	_, ok := fieldInfo.options["raw"]
+	return ok
+}
+
+// normalize applies the normalization option of the field to cell.
+func (fieldInfo fieldInfo) normalize(cell string) string {
+	for option, normalization := range normalizations {
+		if _, ok := fieldInfo.options[option]; ok {
+			return normalization(cell)
+		}
+	}
+	return cell
+}
+
+// unquote removes one pair of matching single or double quotes around cell, which are part of
+// the value and not csv quoting, e.g. '123' with the option unquote. Unbalanced quotes are kept.
+func unquote(cell string) string {
+	if len(cell) >= 2 && (cell[0] == '"' || cell[0] == '\'') && cell[len(cell)-1] == cell[0] {
+		return cell[1 : len(cell)-1]
+	}
+	return cell
+}
+
+// title upper cases the first letter of every word and lower cases all others.
+func title(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		start := !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && prev != '\''
+		prev = r
+		if start {
+			return unicode.ToTitle(r)
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
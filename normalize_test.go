@@ -0,0 +1,157 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type NormalizeStruct struct {
+	Code    string            `csv:"CODE,upper"`
+	Mail    string            `csv:"MAIL,lower"`
+	Name    string            `csv:"NAME,title,default=unknown"`
+	Country country           `csv:"COUNTRY,upper,normalizetext"`
+	Tags    map[string]string `csv:"TAG_*,glob,lower"`
+}
+
+func TestUnmarshalNormalize(t *testing.T) {
+	data := `CODE;MAIL;NAME;COUNTRY;TAG_a
+ch;John.Doe@Example.COM;jOHN o'neil-smith;ch;Foo
+Ch;a@b.c;;De;BAR`
+	m, err := NewMarshaler(NormalizeStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatalf("error in UnmarshalCSV: %s", err)
+	}
+	want := []interface{}{
+		NormalizeStruct{Code: "CH", Mail: "john.doe@example.com", Name: "John O'neil-Smith", Country: "CH", Tags: map[string]string{"a": "foo"}},
+		NormalizeStruct{Code: "CH", Mail: "a@b.c", Name: "Unknown", Country: "DE", Tags: map[string]string{"a": "bar"}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+}
+
+func TestNormalizeInvalidTags(t *testing.T) {
+	var invalidTagTests = map[string]interface{}{
+		"multiple": struct {
+			F string `csv:"F,upper,lower"`
+		}{},
+		"int": struct {
+			F int `csv:"F,title"`
+		}{},
+		"glob elements": struct {
+			F map[string]int `csv:"F_*,glob,upper"`
+		}{},
+		"text only": struct {
+			F country `csv:"F,normalizetext"`
+		}{},
+		"text unmarshaler without normalizetext": struct {
+			F country `csv:"F,upper"`
+		}{},
+	}
+	for name, s := range invalidTagTests {
+		if _, err := NewSchema(s); err == nil {
+			t.Errorf("no error for test '%s', but it should", name)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	var titleTests = map[string]string{
+		"":            "",
+		"zürich":      "Zürich",
+		"NEW YORK":    "New York",
+		"saint-louis": "Saint-Louis",
+		"o'neil 2nd":  "O'neil 2nd",
+	}
+	for in, want := range titleTests {
+		if got := title(in); got != want {
+			t.Errorf("wrong title for %q - want: %q, got: %q", in, want, got)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	var unquoteTests = map[string]string{
+		"":        "",
+		"'":       "'",
+		"''":      "",
+		"'123'":   "123",
+		`"ABC"`:   "ABC",
+		`'ABC"`:   `'ABC"`,
+		"'123":    "'123",
+		"''a''":   "'a'",
+		"it's":    "it's",
+		`"a" "b"`: `a" "b`,
+	}
+	for in, want := range unquoteTests {
+		if got := unquote(in); got != want {
+			t.Errorf("wrong unquote for %q - want: %q, got: %q", in, want, got)
+		}
+	}
+
+	type Quoted struct {
+		Code  int    `csv:"CODE,unquote"`
+		Name  string `csv:"NAME,unquote,default=none"`
+		Plain string `csv:"PLAIN"`
+	}
+	m, err := NewMarshaler(Quoted{}, strings.NewReader("CODE,NAME,PLAIN\n\"'123'\",'',\"\"\"ABC\"\"\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{Quoted{Code: 123, Name: "none", Plain: `"ABC"`}}; !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	type Contact struct {
+		Zip   string `csv:"ZIP,raw"`
+		Phone string `csv:"PHONE,raw"`
+		Note  string `csv:"NOTE,raw"`
+		Name  string `csv:"NAME"`
+	}
+	data := "ZIP\tPHONE\tNOTE\tNAME\n00410\t+41 44 000 00 00\t  a\\tb  \t\\tc\n\t\t\t\n"
+	m, err := NewTSVMarshaler(Contact{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.BackslashEscapes = true
+	m.UsePrototypeDefaults = true
+	if err := m.RegisterConverter(reflect.TypeOf(""), func(s string) (string, error) { return strings.TrimSpace(s), nil }); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// escapes and the converter only apply to NAME
+	want := []interface{}{
+		Contact{Zip: "00410", Phone: "+41 44 000 00 00", Note: `  a\tb  `, Name: "c"},
+		Contact{},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %q, got: %q", want, result)
+	}
+
+	type Number struct {
+		Zip int `csv:"ZIP,raw"`
+	}
+	type Default struct {
+		Zip string `csv:"ZIP,raw,default=0000"`
+	}
+	for _, invalid := range []interface{}{Number{}, Default{}} {
+		if _, err := NewSchema(invalid); err == nil {
+			t.Errorf("no error for invalid raw field %T, but it should", invalid)
+		}
+	}
+}
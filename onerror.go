@@ -0,0 +1,44 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRowSkipped = errors.New("row skipped by onerror=skiprow")
+	ErrZeroedCell = errors.New("cell zeroed by onerror=zero")
+)
+
+// ConversionErrorAction defines how a cell that cannot be converted to the type of its field is
+// handled, per field with the tag option onerror, e.g. `csv:"AGE,onerror=zero"`, or for all fields
+// without option with Marshaler.OnConversionError. Failing validations like enum or min are not
+// conversion errors.
+type ConversionErrorAction string
+
+const (
+	FailOnError    ConversionErrorAction = "fail"    // the row fails with a FieldError, the default
+	ZeroOnError    ConversionErrorAction = "zero"    // the field keeps its zero value, counted as ZeroedCells and recorded as ErrZeroedCell warning in the Report
+	SkipRowOnError ConversionErrorAction = "skiprow" // the row is dropped without error, counted as SkippedRows in the Report
+)
+
+// validateOnError checks the value of the onerror option.
+func validateOnError(options tagOptions) error {
+	action, ok := options["onerror"]
+	if !ok {
+		return nil
+	}
+	switch ConversionErrorAction(action) {
+	case FailOnError, ZeroOnError, SkipRowOnError:
+		return nil
+	}
+	return fmt.Errorf("invalid onerror %s, expected one of fail, zero or skiprow", action)
+}
+
+// onError returns the action for conversion errors of the field.
+func (m *Marshaler) onError(fieldInfo fieldInfo) ConversionErrorAction {
+	if action, ok := fieldInfo.options["onerror"]; ok {
+		return ConversionErrorAction(action)
+	}
+	return m.OnConversionError
+}
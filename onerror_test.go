@@ -0,0 +1,75 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type OnErrorStruct struct {
+	Name string `csv:"NAME"`
+	Age  int    `csv:"AGE,onerror=zero,min=18"`
+	Size int    `csv:"SIZE,onerror=skiprow"`
+	Rank int    `csv:"RANK"`
+}
+
+func TestOnError(t *testing.T) {
+	data := "NAME,AGE,SIZE,RANK\na,x,1,1\nb,20,x,2\nc,30,3,x\nd,10,4,4\n"
+	tt := map[string]struct {
+		action  ConversionErrorAction
+		want    []interface{}
+		errs    int
+		zeroed  int
+		skipped int
+	}{
+		"fail": {
+			action:  FailOnError,
+			want:    []interface{}{OnErrorStruct{Name: "a", Size: 1, Rank: 1}},
+			errs:    2,
+			zeroed:  1,
+			skipped: 1,
+		},
+		"zero": {
+			action:  ZeroOnError,
+			want:    []interface{}{OnErrorStruct{Name: "a", Size: 1, Rank: 1}, OnErrorStruct{Name: "c", Age: 30, Size: 3}},
+			errs:    1,
+			zeroed:  2,
+			skipped: 1,
+		},
+		"skip row": {
+			action:  SkipRowOnError,
+			want:    []interface{}{OnErrorStruct{Name: "a", Size: 1, Rank: 1}},
+			errs:    1,
+			zeroed:  1,
+			skipped: 2,
+		},
+	}
+	for name, tc := range tt {
+		m, err := NewMarshaler(OnErrorStruct{}, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.OnConversionError = tc.action
+		result, err := m.Unmarshal()
+		var errs ParseErrors
+		if !errors.As(err, &errs) || len(errs) != tc.errs {
+			t.Errorf("%s: wrong errors - want: %d ParseErrors, got: %v", name, tc.errs, err)
+		}
+		if !reflect.DeepEqual(result, tc.want) {
+			t.Errorf("%s: wrong result - want: %v, got: %v", name, tc.want, result)
+		}
+		report := m.Report()
+		if report.ZeroedCells != tc.zeroed || report.SkippedRows != tc.skipped || report.Rows != 4 {
+			t.Errorf("%s: wrong report - want: %d zeroed, %d skipped and 4 rows, got: %d, %d and %d",
+				name, tc.zeroed, tc.skipped, report.ZeroedCells, report.SkippedRows, report.Rows)
+		}
+	}
+
+	type Invalid struct {
+		Age int `csv:"AGE,onerror=ignore"`
+	}
+	if _, err := NewSchema(Invalid{}); err == nil {
+		t.Error("no error for invalid onerror, but it should")
+	}
+}
@@ -0,0 +1,71 @@
+package csv
+
+// Option configures a Marshaler.
+type Option func(m *Marshaler)
+
+// WithLazyQuotes allows quotes in unquoted fields and non-doubled quotes in quoted fields,
+// see csv.Reader.LazyQuotes.
+func WithLazyQuotes() Option {
+	return func(m *Marshaler) {
+		m.Reader.LazyQuotes = true
+	}
+}
+
+// WithTrimLeadingSpace ignores leading white space of cells, see csv.Reader.TrimLeadingSpace.
+func WithTrimLeadingSpace() Option {
+	return func(m *Marshaler) {
+		m.Reader.TrimLeadingSpace = true
+	}
+}
+
+// WithComment skips lines starting with the comment character c, see csv.Reader.Comment.
+func WithComment(c rune) Option {
+	return func(m *Marshaler) {
+		m.Reader.Comment = c
+	}
+}
+
+// WithDelimiter separates the cells by delimiter, see Marshaler.DelimiterString.
+func WithDelimiter(delimiter string) Option {
+	return func(m *Marshaler) {
+		m.DelimiterString = delimiter
+	}
+}
+
+// WithHeaderAliases maps old header names to the canonical csv tag names, e.g. to accept the header
+// of files written before a column was renamed. Used aliases are listed in the Report. If a header
+// contains both names, the canonical column is decoded, see ErrorOnAliasConflict.
+func WithHeaderAliases(aliases map[string]string) Option {
+	return func(m *Marshaler) {
+		if m.aliases == nil {
+			m.aliases = map[string]string{}
+		}
+		for old, canonical := range aliases {
+			m.aliases[old] = canonical
+		}
+	}
+}
+
+// WithSource labels the errors and the Report with the source of the input, e.g. the file name.
+// Errors of the csv format are wrapped in a FieldError carrying the label.
+func WithSource(label string) Option {
+	return func(m *Marshaler) {
+		m.label = label
+	}
+}
+
+// condition requires the cell of the column header to be value.
+type condition struct {
+	header string
+	value  string
+}
+
+// WhereColumn skips data rows whose cell of the column header is not value before they are decoded,
+// e.g. to read one of several record types interleaved in one file. Multiple conditions must all
+// match. Skipped rows are counted as FilteredRows in the Report and do not count towards the
+// number of rows of Preview. A header name without column is an error at the first data row.
+func WhereColumn(header, value string) Option {
+	return func(m *Marshaler) {
+		m.where = append(m.where, condition{header: header, value: value})
+	}
+}
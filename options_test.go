@@ -0,0 +1,194 @@
+package csv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	tt := map[string]struct {
+		data string
+		opts []Option
+		want []interface{}
+	}{
+		"lazy quotes": {
+			data: "FIELD_0,FIELD_1,FIELD_2,FIELD_3\n12\" pipe,1,true,1.5\n\"a \"quoted\" word\",2,false,2.5\n",
+			opts: []Option{WithLazyQuotes()},
+			want: []interface{}{
+				TestStruct{Field0: `12" pipe`, Field1: 1, Field2: true, Field3: 1.5},
+				TestStruct{Field0: `a "quoted" word`, Field1: 2, Field2: false, Field3: 2.5},
+			},
+		},
+		"trim leading space": {
+			data: "FIELD_0, FIELD_1, FIELD_2, FIELD_3\nstring,  1, true,\t1.5\n",
+			opts: []Option{WithTrimLeadingSpace()},
+			want: []interface{}{TestStruct{Field0: "string", Field1: 1, Field2: true, Field3: 1.5}},
+		},
+		"comment": {
+			data: "# export of 2020-01-01\nFIELD_0,FIELD_1,FIELD_2,FIELD_3\nstring,1,true,1.5\n# no more rows\n",
+			opts: []Option{WithComment('#')},
+			want: []interface{}{TestStruct{Field0: "string", Field1: 1, Field2: true, Field3: 1.5}},
+		},
+	}
+	for name, tc := range tt {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(tc.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Unmarshal(); err == nil {
+			t.Errorf("%s: no error without option, but it should", name)
+		}
+		m, err = NewMarshaler(TestStruct{}, strings.NewReader(tc.data), tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := m.Unmarshal()
+		if err != nil {
+			t.Errorf("%s: error in Unmarshal: %s", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tc.want) {
+			t.Errorf("%s: wrong result - want: %v, got: %v", name, tc.want, result)
+		}
+	}
+}
+
+func TestWithHeaderAliases(t *testing.T) {
+	aliases := WithHeaderAliases(map[string]string{"F0": "FIELD_0", "F3": "FIELD_3"})
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader("F0,FIELD_1,FIELD_2,FIELD_3\nstring,1,true,1.5\n"), aliases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{TestStruct{Field0: "string", Field1: 1, Field2: true, Field3: 1.5}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	if used := m.Report().Aliases; !reflect.DeepEqual(used, map[string]string{"F0": "FIELD_0"}) {
+		t.Errorf("wrong used aliases - want: map[F0:FIELD_0], got: %v", used)
+	}
+
+	// the canonical column wins over an old one
+	data := "F0,FIELD_0,FIELD_1,FIELD_2,FIELD_3\nold,new,1,true,1.5\n"
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data), aliases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].(TestStruct).Field0 != "new" || m.Report().Aliases != nil {
+		t.Errorf("wrong result for conflicting names - want: new without aliases, got: %v %v", result, m.Report().Aliases)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data), aliases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ErrorOnAliasConflict = true
+	if _, err := m.Unmarshal(); !errors.Is(err, ErrAliasConflict) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrAliasConflict, err)
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\nstring,x,true,1.5\nstring,1,true\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithSource("a.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 2 {
+		t.Fatalf("wrong errors - want 2 ParseErrors, got: %v", err)
+	}
+	for _, e := range pe {
+		var fe *FieldError
+		if !errors.As(e.Err, &fe) || fe.Source != "a.csv" || !strings.Contains(e.Error(), "source:a.csv") {
+			t.Errorf("error without source: %v", e)
+		}
+	}
+	if !errors.Is(pe[1].Err, csv.ErrFieldCount) {
+		t.Errorf("wrong error - want: %s, got: %v", csv.ErrFieldCount, pe[1])
+	}
+	js, err := json.Marshal(pe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(js), `"source":"a.csv"`) {
+		t.Errorf("json error without source: %s", js)
+	}
+	if report := m.Report(); report.Source != "a.csv" {
+		t.Errorf("wrong report source - want: a.csv, got: %q", report.Source)
+	}
+}
+
+func TestWhereColumn(t *testing.T) {
+	type Order struct {
+		Type string `csv:"TYPE"`
+		ID   int    `csv:"ID"`
+	}
+	data := "TYPE,ID,STATE\nORDER,1,open\nINVOICE,x,open\nORDER,2,closed\nORDER,3,open\n"
+	tt := map[string]struct {
+		opts     []Option
+		want     []interface{}
+		filtered int
+	}{
+		"single": {
+			opts:     []Option{WhereColumn("TYPE", "ORDER")},
+			want:     []interface{}{Order{"ORDER", 1}, Order{"ORDER", 2}, Order{"ORDER", 3}},
+			filtered: 1,
+		},
+		"all conditions": {
+			opts:     []Option{WhereColumn("TYPE", "ORDER"), WhereColumn("STATE", "open")},
+			want:     []interface{}{Order{"ORDER", 1}, Order{"ORDER", 3}},
+			filtered: 2,
+		},
+	}
+	for name, tc := range tt {
+		m, err := NewMarshaler(Order{}, strings.NewReader(data), tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := m.Unmarshal()
+		if err != nil {
+			t.Errorf("%s: error in Unmarshal: %s", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tc.want) {
+			t.Errorf("%s: wrong result - want: %v, got: %v", name, tc.want, result)
+		}
+		if report := m.Report(); report.FilteredRows != tc.filtered || report.Rows != len(tc.want) {
+			t.Errorf("%s: wrong report - want: %d filtered and %d rows, got: %d and %d", name, tc.filtered, len(tc.want), report.FilteredRows, report.Rows)
+		}
+	}
+
+	m, err := NewMarshaler(Order{}, strings.NewReader(data), WhereColumn("TYPE", "ORDER"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rows, _, err := m.Preview(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{Order{"ORDER", 1}, Order{"ORDER", 2}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("wrong preview - want: %v, got: %v", want, rows)
+	}
+
+	m, err = NewMarshaler(Order{}, strings.NewReader(data), WhereColumn("KIND", "ORDER"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrUnknownColumn, err)
+	}
+}
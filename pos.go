@@ -0,0 +1,64 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	ErrPinnedColumn = errors.New("pinned column beyond header")
+)
+
+// validatePos checks the pos option, the zero based column a field is bound to regardless of the
+// header, e.g. `csv:"STREET,pos=4"` for files whose header names are localized.
+func validatePos(options tagOptions, parts []string) error {
+	spec, ok := options["pos"]
+	if !ok {
+		return nil
+	}
+	if pos, err := strconv.Atoi(spec); err != nil || pos < 0 {
+		return fmt.Errorf("option pos requires a column index >= 0, got: %s", spec)
+	}
+	if len(parts) > 0 {
+		return errors.New("option pos cannot be combined with a composite header name")
+	}
+	for _, option := range []string{"glob", "group", "join", "cols"} {
+		if _, ok := options[option]; ok {
+			return fmt.Errorf("option pos cannot be combined with option %s", option)
+		}
+	}
+	return nil
+}
+
+// pinned returns the column of the pos option and if the field has one.
+func (fieldInfo fieldInfo) pinned() (int, bool) {
+	spec, ok := fieldInfo.options["pos"]
+	if !ok {
+		return 0, false
+	}
+	pos, _ := strconv.Atoi(spec) // checked by validatePos
+	return pos, true
+}
+
+// pinnedColumns returns the columns of the fields with pos option.
+func (fieldInfos fieldInfos) pinnedColumns() map[int]bool {
+	pinned := map[int]bool{}
+	for _, fieldInfo := range fieldInfos {
+		if pos, ok := fieldInfo.pinned(); ok {
+			pinned[pos] = true
+		}
+	}
+	return pinned
+}
+
+// resolvePinned sets the position of a field with pos option, columns beyond the header are an
+// ErrPinnedColumn.
+func (fieldInfo *fieldInfo) resolvePinned(header []string) error {
+	pos, _ := fieldInfo.pinned()
+	if pos >= len(header) {
+		return fmt.Errorf("%w: column %d of field %s, the header has %d columns", ErrPinnedColumn, pos, fieldInfo.fieldName, len(header))
+	}
+	fieldInfo.position = pos
+	return nil
+}
@@ -0,0 +1,95 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type PinnedStruct struct {
+	Name   string `csv:"NAME"`
+	Street string `csv:"STREET,pos=1"`
+	Zip    int    `csv:"ZIP,pos=2"`
+}
+
+func TestPinnedColumn(t *testing.T) {
+	tt := map[string]struct {
+		data    string
+		want    []interface{}
+		wantErr error
+	}{
+		"english header": {
+			data: "NAME,STREET,ZIP\na,Main Street,8000\n",
+			want: []interface{}{PinnedStruct{Name: "a", Street: "Main Street", Zip: 8000}},
+		},
+		"localized header": {
+			data: "NAME,Strasse,PLZ\na,Bahnhofstrasse,8001\n",
+			want: []interface{}{PinnedStruct{Name: "a", Street: "Bahnhofstrasse", Zip: 8001}},
+		},
+		"named field moved": {
+			data: "Ort,Strasse,PLZ,NAME\nZurich,Bahnhofstrasse,8001,a\n",
+			want: []interface{}{PinnedStruct{Name: "a", Street: "Bahnhofstrasse", Zip: 8001}},
+		},
+		"numeric header cell": {
+			data: "NAME,1,2\na,Main Street,8000\n",
+			want: []interface{}{PinnedStruct{Name: "a", Street: "Main Street", Zip: 8000}},
+		},
+		"header too narrow": {
+			data:    "NAME,STREET\na,Main Street\n",
+			wantErr: ErrPinnedColumn,
+		},
+		"named field missing": {
+			data:    "Nom,Rue,NPA\na,Rue du Lac,1000\n",
+			wantErr: ErrHeaderNotComplete,
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(PinnedStruct{}, strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.Unmarshal()
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("wrong error - want: %v, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tc.want) {
+				t.Errorf("wrong result - want: %v, got: %v", tc.want, result)
+			}
+		})
+	}
+}
+
+func TestPinnedColumnTags(t *testing.T) {
+	tt := map[string]interface{}{
+		"negative": struct {
+			F string `csv:"F,pos=-1"`
+		}{},
+		"no number": struct {
+			F string `csv:"F,pos=x"`
+		}{},
+		"glob": struct {
+			F map[string]string `csv:"F_*,glob,pos=1"`
+		}{},
+		"composite": struct {
+			F string `csv:"A+B,composite,pos=1"`
+		}{},
+		"group": struct {
+			F []Audit `csv:"F_*,group,pos=1"`
+		}{},
+	}
+	for name, s := range tt {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewMarshaler(s, strings.NewReader("")); err == nil || !strings.Contains(err.Error(), "option pos") {
+				t.Errorf("wrong error - want: invalid pos option, got: %v", err)
+			}
+		})
+	}
+}
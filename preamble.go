@@ -0,0 +1,89 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultCommentPrefix is the prefix of comment lines written by WriteComments and read with
+// WithPreamble if no other prefix is set.
+const DefaultCommentPrefix = "# "
+
+var (
+	ErrHeaderWritten = errors.New("header already written")
+)
+
+// WriteComments writes every comment as a line starting with CommentPrefix before the header,
+// e.g. "# generated: 2024-05-01". Comments must not contain line breaks.
+func (w *Writer) WriteComments(comments []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.headerWritten {
+		return ErrHeaderWritten
+	}
+	if err := w.start(); err != nil {
+		w.err = err
+		return err
+	}
+	prefix := w.CommentPrefix
+	if len(prefix) == 0 {
+		prefix = DefaultCommentPrefix
+	}
+	for _, comment := range comments {
+		if strings.ContainsAny(comment, "\r\n") {
+			return fmt.Errorf("comment contains a line break: %q", comment)
+		}
+		if err := w.out.check(prefix + comment); err != nil {
+			return err
+		}
+	}
+	for _, comment := range comments {
+		if _, err := fmt.Fprintf(w.out, "%s%s\n", prefix, comment); err != nil {
+			w.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// WithPreamble collects the lines starting with prefix before the header, they are returned by
+// Preamble without prefix. The first rune of prefix becomes the comment character of the Reader
+// if none is set, so that the lines are not read as header. An empty prefix is DefaultCommentPrefix.
+func WithPreamble(prefix string) Option {
+	return func(m *Marshaler) {
+		if len(prefix) == 0 {
+			prefix = DefaultCommentPrefix
+		}
+		m.preamblePrefix = prefix
+		if m.Reader.Comment == 0 {
+			m.Reader.Comment, _ = utf8.DecodeRuneInString(prefix)
+		}
+	}
+}
+
+// Preamble returns the lines before the header of the last parsed file collected with
+// WithPreamble, nil if there are none.
+func (m *Marshaler) Preamble() []string {
+	if m.preamble == nil {
+		return nil
+	}
+	return append([]string{}, m.preamble...)
+}
+
+// collectPreamble collects the preamble from the lines of the header record.
+func (m *Marshaler) collectPreamble() {
+	m.preamble = nil
+	if len(m.preamblePrefix) == 0 {
+		return
+	}
+	for _, line := range bytes.SplitAfter(m.lines.lines, []byte("\n")) {
+		line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+		if bytes.HasPrefix(line, []byte(m.preamblePrefix)) {
+			m.preamble = append(m.preamble, string(line[len(m.preamblePrefix):]))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package csv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPreambleRoundTrip(t *testing.T) {
+	comments := []string{"generated: 2024-05-01", "source: sap", "", "  indented, with comma"}
+	structs := []interface{}{TestStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5}}
+	for _, prefix := range []string{"", "## ", "//"} {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(TestStruct{}, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.CommentPrefix = prefix
+		if err := w.WriteComments(comments); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Marshal(structs); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteComments(comments); err != ErrHeaderWritten {
+			t.Errorf("%q: wrong error after header - want: %s, got: %v", prefix, ErrHeaderWritten, err)
+		}
+
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(buf.String()), WithPreamble(prefix))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := m.Unmarshal()
+		if err != nil {
+			t.Fatalf("%q: error in Unmarshal: %s\n%s", prefix, err, buf)
+		}
+		if !reflect.DeepEqual(result, structs) {
+			t.Errorf("%q: wrong result - want: %v, got: %v", prefix, structs, result)
+		}
+		if got := m.Preamble(); !reflect.DeepEqual(got, comments) {
+			t.Errorf("%q: wrong preamble - want: %q, got: %q", prefix, comments, got)
+		}
+	}
+}
+
+func TestPreamble(t *testing.T) {
+	data := "# generated: 2024-05-01\n#no preamble\nFIELD_0,FIELD_1,FIELD_2,FIELD_3\n# not before the header\na,1,true,1.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithPreamble(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"generated: 2024-05-01"}; !reflect.DeepEqual(m.Preamble(), want) {
+		t.Errorf("wrong preamble - want: %q, got: %q", want, m.Preamble())
+	}
+
+	w, err := NewWriter(TestStruct{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteComments([]string{"two\nlines"}); err == nil {
+		t.Error("no error for a comment with line break, but it should")
+	}
+}
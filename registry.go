@@ -0,0 +1,49 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	ErrTypeRegistered = errors.New("type already registered")
+)
+
+// registeredType is the conversion of a type registered with RegisterType.
+type registeredType struct {
+	parse  func(string) (interface{}, error)
+	format func(interface{}) (string, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[reflect.Type]registeredType{}
+)
+
+// RegisterType registers the conversion of fields of type t for every Marshaler and Writer,
+// e.g. in an init function for third-party types like dates or decimals. parse returns a value
+// assignable to t, format receives a value of type t. Converters of Marshaler.RegisterConverter
+// and formatters of Writer.RegisterFormatter take precedence. A type can only be registered once,
+// further registrations return ErrTypeRegistered.
+func RegisterType(t reflect.Type, parse func(string) (interface{}, error), format func(interface{}) (string, error)) error {
+	if t == nil || parse == nil || format == nil {
+		return errors.New("RegisterType requires a type, a parse and a format function")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[t]; ok {
+		return fmt.Errorf("%w: %s", ErrTypeRegistered, t)
+	}
+	registry[t] = registeredType{parse: parse, format: format}
+	return nil
+}
+
+// lookupType returns the conversion registered with RegisterType for t.
+func lookupType(t reflect.Type) (registeredType, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	rt, ok := registry[t]
+	return rt, ok
+}
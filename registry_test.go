@@ -0,0 +1,123 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Date is a calendar date without time zone like civil.Date.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+type DateStruct struct {
+	Name string `csv:"NAME"`
+	Day  Date   `csv:"DAY"`
+}
+
+var errRegisterDate = RegisterType(reflect.TypeOf(Date{}),
+	func(s string) (interface{}, error) {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, err
+		}
+		return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}, nil
+	},
+	func(v interface{}) (string, error) {
+		d := v.(Date)
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day), nil
+	})
+
+func TestRegisterType(t *testing.T) {
+	if errRegisterDate != nil {
+		t.Fatal(errRegisterDate)
+	}
+	data := "NAME,DAY\na,2024-12-24\nb,2024-12-31\n"
+	m, err := NewMarshaler(DateStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{DateStruct{Name: "a", Day: Date{2024, time.December, 24}}, DateStruct{Name: "b", Day: Date{2024, time.December, 31}}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(DateStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal(result); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != data {
+		t.Errorf("wrong output - want: %q, got: %q", data, buf.String())
+	}
+
+	// invalid cells are decode errors
+	m, err = NewMarshaler(DateStruct{}, strings.NewReader("NAME,DAY\na,24.12.2024\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Unmarshal()
+	pe, ok := err.(ParseErrors)
+	var fe *FieldError
+	if !ok || len(pe) != 1 || !errors.As(pe[0].Err, &fe) || fe.Header != "DAY" {
+		t.Errorf("wrong error - want: error for DAY, got: %v", err)
+	}
+
+	// registrations of the Marshaler and the Writer take precedence
+	m, err = NewMarshaler(DateStruct{}, strings.NewReader("NAME,DAY\na,24.12.2024\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterConverter(reflect.TypeOf(Date{}), func(s string) (Date, error) {
+		t, err := time.Parse("02.01.2006", s)
+		return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	result, err = m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result[0].(DateStruct).Day; got != (Date{2024, time.December, 24}) {
+		t.Errorf("wrong date - want: 2024-12-24, got: %v", got)
+	}
+	buf.Reset()
+	if w, err = NewWriter(DateStruct{}, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.RegisterFormatter(reflect.TypeOf(Date{}), func(d Date) (string, error) {
+		return fmt.Sprintf("%02d.%02d.%04d", d.Day, d.Month, d.Year), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal(result); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME,DAY\na,24.12.2024\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+
+	// a type is registered only once
+	err = RegisterType(reflect.TypeOf(Date{}), func(string) (interface{}, error) { return Date{}, nil },
+		func(interface{}) (string, error) { return "", nil })
+	if !errors.Is(err, ErrTypeRegistered) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrTypeRegistered, err)
+	}
+	if err := RegisterType(reflect.TypeOf(Date{}), nil, nil); err == nil {
+		t.Errorf("no error for registration without functions")
+	}
+}
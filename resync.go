@@ -0,0 +1,100 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+// lineReader hands the input line by line to the csv.Reader of a Marshaler. That way the
+// csv.Reader never reads ahead and the lines of the current record are known, which allows
+// to replay the lines a broken record swallowed.
+type lineReader struct {
+	r        *bufio.Reader
+	pending  []byte                   // lines to hand out before reading r
+	lines    []byte                   // lines handed out since the last mark
+	line     int                      // number of lines handed out, counting replayed lines again like encoding/csv
+	start    int                      // line number of the first line since the last mark
+	replayed int                      // number of lines encoding/csv counts twice, replayed lines and the end of the input before a replay
+	eof      bool                     // if true, the end of the input was handed out after a complete line
+	newline  bool                     // if true, the last handed out byte ended a line
+	bytes    int64                    // number of handed out bytes that are not part of the input, replayed or added by split
+	split    func(line []byte) []byte // translates the lines of multi-rune delimiters and fixed-width files, nil for other files
+}
+
+func newLineReader(r *bufio.Reader) *lineReader {
+	return &lineReader{r: r, newline: true}
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	if len(lr.pending) == 0 {
+		line, err := lr.readLine()
+		if len(line) == 0 {
+			if err == bufio.ErrBufferFull {
+				err = nil
+			}
+			lr.eof = err == io.EOF && lr.newline
+			return 0, err
+		}
+		lr.pending = append(lr.pending, line...)
+	}
+	line := lr.pending
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i+1]
+	}
+	n := copy(p, line)
+	lr.pending = lr.pending[n:]
+	lr.lines = append(lr.lines, p[:n]...)
+	lr.line += bytes.Count(p[:n], []byte("\n"))
+	lr.newline = n > 0 && p[n-1] == '\n' || n == 0 && lr.newline
+	return n, nil
+}
+
+// readLine reads the next line of r, a part of it for long lines unless the lines are split.
+func (lr *lineReader) readLine() ([]byte, error) {
+	if lr.split == nil {
+		return lr.r.ReadSlice('\n')
+	}
+	line, err := lr.r.ReadBytes('\n')
+	if len(line) == 0 {
+		return nil, err
+	}
+	translated := lr.split(line)
+	lr.bytes += int64(len(translated) - len(line))
+	return translated, nil
+}
+
+// mark starts a new record.
+func (lr *lineReader) mark() {
+	lr.lines = lr.lines[:0]
+	lr.start = lr.line + 1
+}
+
+// resync replays the lines after the first line of the broken record of pe, which encoding/csv
+// consumed while looking for the end of an unterminated quoted field. Only the first line of the
+// record is lost.
+func (lr *lineReader) resync(pe *csv.ParseError) {
+	if !errors.Is(pe.Err, csv.ErrQuote) && !errors.Is(pe.Err, csv.ErrBareQuote) {
+		return
+	}
+	lines := bytes.SplitAfter(lr.lines, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	skip := pe.StartLine - lr.start + 1
+	if skip < 1 || skip >= len(lines) {
+		return
+	}
+	replay := bytes.Join(lines[skip:], nil)
+	lr.pending = append(replay, lr.pending...)
+	lr.lines = lr.lines[:0]
+	lr.replayed += len(lines) - skip
+	if lr.eof { // encoding/csv counted a line for the end of the input
+		lr.replayed++
+		lr.line++
+		lr.eof = false
+	}
+	lr.bytes += int64(len(replay))
+}
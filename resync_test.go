@@ -0,0 +1,72 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLazyResync(t *testing.T) {
+	data := `FIELD_0,FIELD_1,FIELD_2,FIELD_3
+first,1,true,1.5
+"broken,2,true,1.5
+third,3,true,1.5
+"fourth",4,true,1.5
+fifth,5,true,1.5`
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	result, err := m.Unmarshal()
+	want := []interface{}{
+		TestStruct{Field0: "first", Field1: 1, Field2: true, Field3: 1.5},
+		TestStruct{Field0: "third", Field1: 3, Field2: true, Field3: 1.5},
+		TestStruct{Field0: "fourth", Field1: 4, Field2: true, Field3: 1.5},
+		TestStruct{Field0: "fifth", Field1: 5, Field2: true, Field3: 1.5},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 1 {
+		t.Fatalf("wrong errors - want 1 ParseError, got: %v", err)
+	}
+	if pe[0].StartLine != 3 || !errors.Is(pe[0].Err, csv.ErrQuote) {
+		t.Errorf("wrong error - want: quote error starting in line 3, got: %v", pe[0])
+	}
+
+	// without Lazy the first error is returned
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); !errors.Is(err, csv.ErrQuote) {
+		t.Errorf("wrong error - want: %s, got: %v", csv.ErrQuote, err)
+	}
+}
+
+func TestLazyResyncLineNumbers(t *testing.T) {
+	data := `FIELD_0,FIELD_1,FIELD_2,FIELD_3
+"broken,1,true,1.5
+second,2,true,1.5
+"broken,3,true,1.5
+fourth,x"y,true,1.5`
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 3 {
+		t.Fatalf("wrong errors - want 3 ParseErrors, got: %v", err)
+	}
+	for i, line := range []int{2, 4, 5} {
+		if pe[i].StartLine != line {
+			t.Errorf("wrong start line of error %d - want: %d, got: %d", i, line, pe[i].StartLine)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Integer fields with the scale tag option hold decimal numbers with a fixed number of fractional
+// digits, e.g. `csv:"AMOUNT,scale=2"` decodes the cell "12.34" to 1234 and encodes 1234 as "12.34".
+// With the decimalcomma option the fractional digits are separated by a comma.
+
+// parseScale parses the scale option for a field of type typ, it returns 0 without scale option.
+func parseScale(typ reflect.Type, options tagOptions) (int, error) {
+	text, ok := options["scale"]
+	_, comma := options["decimalcomma"]
+	if !ok {
+		if comma {
+			return 0, fmt.Errorf("option decimalcomma requires the scale option")
+		}
+		return 0, nil
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		return 0, fmt.Errorf("option scale requires an integer field, got: %s", typ)
+	}
+	scale, err := strconv.Atoi(text)
+	if err != nil || scale < 1 || scale > 18 {
+		return 0, fmt.Errorf("invalid scale %q, must be between 1 and 18", text)
+	}
+	return scale, nil
+}
+
+// decimalSeparator returns the separator of the fractional digits of scaled fields.
+func (fieldInfo fieldInfo) decimalSeparator() string {
+	if _, ok := fieldInfo.options["decimalcomma"]; ok {
+		return ","
+	}
+	return "."
+}
+
+// parseScaled parses a decimal number to an integer scaled by 10^scale.
+func (fieldInfo fieldInfo) parseScaled(cell string) (int64, error) {
+	digits, frac := cell, ""
+	if i := strings.Index(cell, fieldInfo.decimalSeparator()); i >= 0 {
+		digits, frac = cell[:i], cell[i+1:]
+	}
+	if strings.TrimLeft(digits, "+-") == "" && frac == "" {
+		return 0, fmt.Errorf("invalid decimal number: %s", cell)
+	}
+	if len(frac) > fieldInfo.scale {
+		return 0, fmt.Errorf("%s has more than %d fractional digits", cell, fieldInfo.scale)
+	}
+	for _, r := range frac {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid decimal number: %s", cell)
+		}
+	}
+	return strconv.ParseInt(digits+frac+strings.Repeat("0", fieldInfo.scale-len(frac)), 10, fieldInfo.typ.Bits())
+}
+
+// formatScaled formats an integer scaled by 10^scale as decimal number.
+func (fieldInfo fieldInfo) formatScaled(i int64) string {
+	sign, u := "", uint64(i)
+	if i < 0 {
+		sign, u = "-", uint64(-i) // -MinInt64 overflows to itself, which is correct as uint64
+	}
+	digits := strconv.FormatUint(u, 10)
+	if len(digits) <= fieldInfo.scale {
+		digits = strings.Repeat("0", fieldInfo.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - fieldInfo.scale
+	return sign + digits[:point] + fieldInfo.decimalSeparator() + digits[point:]
+}
@@ -0,0 +1,128 @@
+package csv
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseScaled(t *testing.T) {
+	var parseScaledTests = []struct {
+		cell  string
+		comma bool
+		want  int64
+		err   bool
+	}{
+		{"12.34", false, 1234, false},
+		{"12", false, 1200, false},
+		{"12.3", false, 1230, false},
+		{"12.", false, 1200, false},
+		{".5", false, 50, false},
+		{"-0.05", false, -5, false},
+		{"+1.00", false, 100, false},
+		{"12,34", true, 1234, false},
+		{"12.34", true, 0, true},
+		{"12.345", false, 0, true},
+		{"", false, 0, true},
+		{"-", false, 0, true},
+		{"1.-5", false, 0, true},
+		{"abc", false, 0, true},
+		{"92233720368547758.07", false, math.MaxInt64, false},
+		{"92233720368547758.08", false, 0, true},
+		{"-92233720368547758.08", false, math.MinInt64, false},
+	}
+	for _, test := range parseScaledTests {
+		options := tagOptions{"scale": "2"}
+		if test.comma {
+			options["decimalcomma"] = ""
+		}
+		fieldInfo := fieldInfo{typ: reflect.TypeOf(int64(0)), options: options, scale: 2}
+		got, err := fieldInfo.parseScaled(test.cell)
+		if (err != nil) != test.err {
+			t.Errorf("wrong error for %q - want error: %t, got: %v", test.cell, test.err, err)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("wrong value for %q - want: %d, got: %d", test.cell, test.want, got)
+		}
+	}
+}
+
+func TestFormatScaled(t *testing.T) {
+	var formatScaledTests = map[int64]string{
+		1234:          "12.34",
+		5:             "0.05",
+		-5:            "-0.05",
+		0:             "0.00",
+		-1200:         "-12.00",
+		math.MinInt64: "-92233720368547758.08",
+	}
+	fieldInfo := fieldInfo{typ: reflect.TypeOf(int64(0)), scale: 2}
+	for i, want := range formatScaledTests {
+		if got := fieldInfo.formatScaled(i); got != want {
+			t.Errorf("wrong format for %d - want: %s, got: %s", i, want, got)
+		}
+	}
+}
+
+type MoneyStruct struct {
+	Amount int64 `csv:"AMOUNT,scale=2,min=-100.00"`
+	Price  int32 `csv:"PRICE,scale=3,decimalcomma"`
+}
+
+func TestScaleRoundTrip(t *testing.T) {
+	data := "AMOUNT;PRICE\n12.34;1,5\n-100.00;0,001\n-100.01;1\n"
+	m, err := NewMarshaler(MoneyStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	want := []interface{}{MoneyStruct{Amount: 1234, Price: 1500}, MoneyStruct{Amount: -10000, Price: 1}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	if pe, ok := err.(ParseErrors); !ok || len(pe) != 1 || pe[0].Line != 4 {
+		t.Errorf("wrong errors - want: min error in line 4, got: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(MoneyStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Writer.Comma = ';'
+	if err := w.Marshal(result); err != nil {
+		t.Fatal(err)
+	}
+	if wantCSV := "AMOUNT;PRICE\n12.34;1,500\n-100.00;0,001\n"; buf.String() != wantCSV {
+		t.Errorf("wrong csv - want: %q, got: %q", wantCSV, buf.String())
+	}
+}
+
+func TestScaleInvalidTags(t *testing.T) {
+	var invalidTagTests = map[string]interface{}{
+		"float": struct {
+			F float64 `csv:"F,scale=2"`
+		}{},
+		"zero": struct {
+			F int `csv:"F,scale=0"`
+		}{},
+		"invalid": struct {
+			F int `csv:"F,scale=x"`
+		}{},
+		"comma only": struct {
+			F int `csv:"F,decimalcomma"`
+		}{},
+		"scaled bound": struct {
+			F int `csv:"F,scale=1,max=1.25"`
+		}{},
+	}
+	for name, s := range invalidTagTests {
+		if _, err := NewSchema(s); err == nil {
+			t.Errorf("no error for test '%s', but it should", name)
+		}
+	}
+}
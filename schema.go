@@ -0,0 +1,180 @@
+package csv
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Schema describes the mapping between an endpoint struct and the csv header. It is
+// created once with NewSchema, never modified afterwards and therefore safe to share
+// between Marshalers and Writers running in different goroutines.
+type Schema struct {
+	fieldInfos     fieldInfos
+	endPointStruct interface{}
+}
+
+// SchemaOption configures the creation of a Schema.
+type SchemaOption func(c *schemaConfig)
+
+type schemaConfig struct {
+	fieldMap map[string]string // csv tags by struct field name of WithFieldMap
+}
+
+// WithFieldMap sets the csv tags of struct fields without csv tag by field name, e.g. for
+// generated structs that cannot be annotated: {"Field0": "FIELD_0,format=2006-01-02"}. Tags of
+// the struct win. Fields that are neither tagged nor mapped are handled as without field map,
+// "-" ignores a field. A field name that is not an exported field of the struct is an error.
+// Marshalers and Writers created with the Schema use the resulting header names.
+func WithFieldMap(fieldMap map[string]string) SchemaOption {
+	return func(c *schemaConfig) {
+		c.fieldMap = fieldMap
+	}
+}
+
+// NewSchema returns the Schema of the endpoint struct configured with opts.
+func NewSchema(endPointStruct interface{}, opts ...SchemaOption) (*Schema, error) {
+	config := &schemaConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	endPointStruct = indirect(endPointStruct)
+	fieldInfos, err := createMappedFieldInfos(endPointStruct, config.fieldMap)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{fieldInfos: fieldInfos, endPointStruct: endPointStruct}, nil
+}
+
+// Header returns the csv header names of the schema in the order of the struct fields,
+// readonly and composite fields are left out.
+func (s *Schema) Header() []string {
+	header := make([]string, 0, len(s.fieldInfos))
+	for _, fieldInfo := range s.fieldInfos.writable() {
+		header = append(header, fieldInfo.headerName)
+	}
+	return header
+}
+
+// Fingerprint returns a hex encoded SHA-256 hash over the header name, kind and tag options of
+// every field. It only depends on this input and not on the Go version: the fields are hashed
+// in struct order, because it defines the order of written columns, the options of a field in
+// alphabetical order. Renaming or reordering struct fields without changing their tags keeps the
+// fingerprint, moving a tagged field changes it.
+func (s *Schema) Fingerprint() string {
+	b := &strings.Builder{}
+	for _, fieldInfo := range s.fieldInfos {
+		writeFingerprint(b, fieldInfo.headerName)
+		writeFingerprint(b, fieldInfo.kind.String())
+		keys := make([]string, 0, len(fieldInfo.options))
+		for key := range fieldInfo.options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			writeFingerprint(b, key)
+			writeFingerprint(b, fieldInfo.options[key])
+		}
+		b.WriteString("\n")
+	}
+	return fingerprint(b.String())
+}
+
+// HeaderFingerprint returns a hex encoded SHA-256 hash over the header names in column order,
+// see Schema.Fingerprint.
+func HeaderFingerprint(header []string) string {
+	b := &strings.Builder{}
+	for _, name := range header {
+		writeFingerprint(b, name)
+	}
+	return fingerprint(b.String())
+}
+
+// writeFingerprint writes s with its length, so that the concatenation is unambiguous.
+func writeFingerprint(b *strings.Builder, s string) {
+	fmt.Fprintf(b, "%d:%s;", len(s), s)
+}
+
+func fingerprint(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateHeader checks the header like the function ValidateHeader.
+func (s *Schema) ValidateHeader(header []string) error {
+	return validateHeader(s.fieldInfos, header, false)
+}
+
+// DecodeRecord decodes a single record with the given header to an endpoint struct with the
+// same conversion, default and validation rules as a Marshaler. Errors of the record are returned
+// as *csv.ParseError with line 0 and the column of the record.
+func (s *Schema) DecodeRecord(header, record []string) (interface{}, error) {
+	m := NewMarshalerWithSchema(s, nil)
+	if err := m.resolveHeader(header); err != nil {
+		return nil, err
+	}
+	sPtr := reflect.New(reflect.TypeOf(s.endPointStruct))
+	if perr := m.decode(sPtr.Interface(), record, 0); perr != nil {
+		return nil, perr
+	}
+	return sPtr.Elem().Interface(), nil
+}
+
+// DecodeRecord decodes a single record with the given header to the endpoint struct s,
+// see Schema.DecodeRecord.
+func DecodeRecord(s interface{}, header, record []string) (interface{}, error) {
+	schema, err := NewSchema(s)
+	if err != nil {
+		return nil, err
+	}
+	return schema.DecodeRecord(header, record)
+}
+
+// NewMarshalerWithSchema returns a new Marshaler for the endpoint struct of schema configured
+// with opts. The parse state is kept per Marshaler, a Marshaler itself must not be used concurrently.
+func NewMarshalerWithSchema(schema *Schema, r io.Reader, opts ...Option) *Marshaler {
+	source := &replayReader{r: r}
+	lines := newLineReader(bufio.NewReader(source))
+	fixedWidth, _ := schema.fieldInfos.isFixedWidth() // checked by NewSchema
+	m := &Marshaler{
+		Reader: csv.NewReader(lines),
+		source: source,
+		lines:  lines,
+		// positions are resolved per file, so the Marshaler needs its own copy
+		fieldInfos:       append(fieldInfos{}, schema.fieldInfos...),
+		allFieldInfos:    schema.fieldInfos,
+		fixedWidth:       fixedWidth,
+		endPointStruct:   schema.endPointStruct,
+		errors:           ParseErrors{},
+		decoders:         map[string]FieldDecoder{},
+		converters:       map[reflect.Type]func(string) (interface{}, error){},
+		headerMap:        map[string]string{},
+		ErrorRateMinRows: 100,
+		Metrics:          noopMetrics{},
+		// header cells are validated unless disabled
+		ValidateHeaderCells: RejectNumericHeaderCell,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// NewWriterWithSchema returns a new Writer for the endpoint struct of schema.
+func NewWriterWithSchema(schema *Schema, w io.Writer) *Writer {
+	out := &outputWriter{w: w}
+	return &Writer{
+		Writer:         csv.NewWriter(out),
+		out:            out,
+		FormulaEscape:  "'",
+		fieldInfos:     schema.fieldInfos.writable(),
+		endPointStruct: schema.endPointStruct,
+		formatters:     map[reflect.Type]func(interface{}) (string, error){},
+	}
+}
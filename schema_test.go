@@ -0,0 +1,190 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSchemaHeader(t *testing.T) {
+	schema, err := NewSchema(&TestStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"FIELD_0", "FIELD_1", "FIELD_2", "FIELD_3"}
+	if got := schema.Header(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong header - want: %v, got: %v", want, got)
+	}
+	if err := schema.ValidateHeader([]string{"FIELD_0", "FIELD_1", "FIELD_2"}); err == nil {
+		t.Error("expected error for incomplete header")
+	}
+	if _, err := NewSchema("no struct"); err != ErrNoStruct {
+		t.Errorf("wrong error - want: %s, got: %v", ErrNoStruct, err)
+	}
+}
+
+func TestSchemaConcurrentUse(t *testing.T) {
+	schema, err := NewSchema(TestStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// every file has a different column order to resolve
+			data := "FIELD_3,FIELD_2,FIELD_1,FIELD_0\n1.5,true," + strconv.Itoa(i) + ",string\n"
+			if i%2 == 0 {
+				data = "FIELD_0,FIELD_1,FIELD_2,FIELD_3\nstring," + strconv.Itoa(i) + ",true,1.5\n"
+			}
+			structs, err := NewMarshalerWithSchema(schema, strings.NewReader(data)).Unmarshal()
+			if err != nil {
+				errs <- err
+				return
+			}
+			want := TestStruct{Field0: "string", Field1: i, Field2: true, Field3: 1.5}
+			if len(structs) != 1 || structs[0] != want {
+				t.Errorf("wrong structs in goroutine %d - want: %v, got: %v", i, want, structs)
+			}
+			buf := &bytes.Buffer{}
+			if err := NewWriterWithSchema(schema, buf).Marshal(structs); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestDecodeRecord(t *testing.T) {
+	header := []string{"FIELD_3", "FIELD_2", "FIELD_1", "FIELD_0"}
+	s, err := DecodeRecord(TestStruct{}, header, []string{"1.5", "true", "1", "string"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (TestStruct{Field0: "string", Field1: 1, Field2: true, Field3: 1.5}); s != want {
+		t.Errorf("wrong struct - want: %v, got: %v", want, s)
+	}
+
+	schema, err := NewSchema(TestStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = schema.DecodeRecord(header, []string{"1.5", "invalid", "1", "string"})
+	var fe *FieldError
+	if pe, ok := err.(*csv.ParseError); !ok || pe.Column != 1 || !errors.As(err, &fe) || fe.Header != "FIELD_2" {
+		t.Errorf("wrong error - want: FIELD_2 error in column 1, got: %v", err)
+	}
+	if _, err := schema.DecodeRecord(header[1:], []string{"true", "1", "string"}); err == nil {
+		t.Error("no error for incomplete header, but it should")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	type Original struct {
+		Name  string `csv:"NAME,default=x,upper"`
+		Count int    `csv:"COUNT"`
+	}
+	type Renamed struct {
+		Title  string `csv:"NAME,upper,default=x"`
+		Amount int    `csv:"COUNT"`
+	}
+	type Reordered struct {
+		Count int    `csv:"COUNT"`
+		Name  string `csv:"NAME,default=x,upper"`
+	}
+	type Changed struct {
+		Name  string `csv:"NAME,default=y,upper"`
+		Count int    `csv:"COUNT"`
+	}
+	type Retyped struct {
+		Name  string  `csv:"NAME,default=x,upper"`
+		Count float64 `csv:"COUNT"`
+	}
+	fingerprints := map[string]string{}
+	for name, s := range map[string]interface{}{"original": Original{}, "renamed": Renamed{}, "reordered": Reordered{}, "changed": Changed{}, "retyped": Retyped{}} {
+		schema, err := NewSchema(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fingerprints[name] = schema.Fingerprint()
+	}
+	// a changed fingerprint breaks persisted fingerprints of users
+	if want := "2068d6ac882364b73dce0376c3404c362f1cba72656cf5007c33a3b1c5b63b93"; fingerprints["original"] != want {
+		t.Errorf("wrong fingerprint - want: %s, got: %s", want, fingerprints["original"])
+	}
+	if fingerprints["renamed"] != fingerprints["original"] {
+		t.Error("renamed fields and reordered options change the fingerprint")
+	}
+	for _, name := range []string{"reordered", "changed", "retyped"} {
+		if fingerprints[name] == fingerprints["original"] {
+			t.Errorf("%s: fingerprint did not change", name)
+		}
+	}
+
+	header := HeaderFingerprint([]string{"NAME", "COUNT"})
+	if header != HeaderFingerprint([]string{"NAME", "COUNT"}) || header == HeaderFingerprint([]string{"COUNT", "NAME"}) {
+		t.Error("header fingerprint does not depend on the column order")
+	}
+	if HeaderFingerprint([]string{"A,B"}) == HeaderFingerprint([]string{"A", "B"}) {
+		t.Error("header fingerprint is ambiguous")
+	}
+}
+
+// GeneratedStruct has no csv tags like a generated struct.
+type GeneratedStruct struct {
+	ID      int
+	Name    string
+	Comment string
+	Tagged  bool `csv:"TAGGED"`
+}
+
+func TestSchemaWithFieldMap(t *testing.T) {
+	fieldMap := map[string]string{"ID": "id", "Name": "name,upper", "Comment": "-", "Tagged": "ignored"}
+	schema, err := NewSchema(GeneratedStruct{}, WithFieldMap(fieldMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"id", "name", "TAGGED"}
+	if got := schema.Header(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong header - want: %v, got: %v", want, got)
+	}
+
+	m := NewMarshalerWithSchema(schema, strings.NewReader("TAGGED,name,id\ntrue,bob,1\n"))
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantResult := []interface{}{GeneratedStruct{ID: 1, Name: "BOB", Tagged: true}}
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Errorf("wrong result - want: %v, got: %v", wantResult, result)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := NewWriterWithSchema(schema, buf).Marshal(result); err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,name,TAGGED\n1,BOB,true\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+
+	// unknown field names fail immediately, unmapped fields need a tag
+	if _, err := NewSchema(GeneratedStruct{}, WithFieldMap(map[string]string{"ID": "id", "Missing": "x", "comment": "y"})); err == nil ||
+		!strings.Contains(err.Error(), "Missing, comment") {
+		t.Errorf("wrong error - want: unknown fields Missing, comment, got: %v", err)
+	}
+	if _, err := NewSchema(GeneratedStruct{}, WithFieldMap(map[string]string{"ID": "id"})); err == nil ||
+		!strings.Contains(err.Error(), "empty csv tag for field: Name") {
+		t.Errorf("wrong error - want: empty csv tag for field Name, got: %v", err)
+	}
+}
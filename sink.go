@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+)
+
+// RecordSink receives the records of UnmarshalToSink, e.g. to insert them in batches.
+type RecordSink interface {
+	Append(v interface{}) error // receives the next decoded endpoint struct
+	Flush() error               // is called once after the last record
+}
+
+// replacer is implemented by sinks that can replace an appended record, which DedupBy with
+// KeepLast requires.
+type replacer interface {
+	replace(i int, v interface{})
+}
+
+// SliceSink is a RecordSink that collects the records in a slice, Unmarshal uses it.
+type SliceSink struct {
+	Records []interface{}
+}
+
+// Append appends v to the Records.
+func (s *SliceSink) Append(v interface{}) error {
+	s.Records = append(s.Records, v)
+	return nil
+}
+
+// Flush does nothing, the records are already in the slice.
+func (s *SliceSink) Flush() error {
+	return nil
+}
+
+func (s *SliceSink) replace(i int, v interface{}) {
+	s.Records[i] = v
+}
+
+// UnmarshalToSink parses a csv file like Unmarshal, but hands every kept record to s instead of
+// returning them and calls Flush at the end of the input. An error of Append aborts the parse
+// with the line of the record, column -1 and the error wrapped in a FieldError. Fatal errors are
+// returned without Flush, errors of single rows are returned as ParseErrors after Flush.
+// DedupBy with KeepLast requires a SliceSink.
+func (m *Marshaler) UnmarshalToSink(s RecordSink) error {
+	if err := m.unmarshalSink(m.Reader, -1, s); err != nil {
+		return err
+	}
+	if len(m.errors) == 0 {
+		return nil
+	}
+	return m.errors
+}
+
+// unmarshalSink reads at most limit data rows (all if limit < 0) into s and flushes it. Only
+// fatal errors are returned, errors of single rows are collected in m.errors.
+func (m *Marshaler) unmarshalSink(r *csv.Reader, limit int, s RecordSink) error {
+	records := &sinkRecords{typ: reflect.TypeOf(m.endPointStruct), sink: s}
+	if _, ok := s.(replacer); !ok && m.dedupField != "" && m.dedupKeep == KeepLast {
+		return errors.New("DedupBy with KeepLast requires a sink that can replace records")
+	}
+	if err := m.unmarshalRecords(r, limit, records); err != nil {
+		return err
+	}
+	return s.Flush()
+}
+
+// sinkRecords allocates a new endpoint struct per record and appends it to a RecordSink.
+type sinkRecords struct {
+	typ  reflect.Type
+	sink RecordSink
+	last reflect.Value
+	n    int
+}
+
+func (r *sinkRecords) next() interface{} {
+	r.last = reflect.New(r.typ)
+	return r.last.Interface()
+}
+
+func (r *sinkRecords) add(i int) error {
+	if i >= 0 {
+		r.sink.(replacer).replace(i, r.last.Elem().Interface())
+		return nil
+	}
+	if err := r.sink.Append(r.last.Elem().Interface()); err != nil {
+		return err
+	}
+	r.n++
+	return nil
+}
+
+func (r *sinkRecords) len() int {
+	return r.n
+}
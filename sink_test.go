@@ -0,0 +1,111 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// batchSink collects the records in batches of size.
+type batchSink struct {
+	size    int
+	batch   []interface{}
+	batches [][]interface{}
+	failAt  int // number of the record Append fails for, 0 if none
+	n       int
+	flushed int
+}
+
+func (s *batchSink) Append(v interface{}) error {
+	s.n++
+	if s.n == s.failAt {
+		return errors.New("insert failed")
+	}
+	s.batch = append(s.batch, v)
+	if len(s.batch) == s.size {
+		s.batches = append(s.batches, s.batch)
+		s.batch = nil
+	}
+	return nil
+}
+
+func (s *batchSink) Flush() error {
+	if len(s.batch) > 0 {
+		s.batches = append(s.batches, s.batch)
+		s.batch = nil
+	}
+	s.flushed++
+	return nil
+}
+
+func TestUnmarshalToSink(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,x,true,2.5\nc,3,false,3.5\nd,4,false,4.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	s := &batchSink{size: 2}
+	err = m.UnmarshalToSink(s)
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 1 || pe[0].Line != 3 {
+		t.Fatalf("wrong errors - want: 1 ParseError on line 3, got: %v", err)
+	}
+	want := [][]interface{}{
+		{TestStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5}, TestStruct{Field0: "c", Field1: 3, Field3: 3.5}},
+		{TestStruct{Field0: "d", Field1: 4, Field3: 4.5}},
+	}
+	if !reflect.DeepEqual(s.batches, want) || s.flushed != 1 {
+		t.Errorf("wrong batches - want: %v flushed once, got: %v flushed %d times", want, s.batches, s.flushed)
+	}
+
+	// Unmarshal returns the records of a SliceSink
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Lazy = true
+	result, _ := m.Unmarshal()
+	if !reflect.DeepEqual(result, append(want[0], want[1]...)) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+
+	// an Append error aborts without Flush, the second kept record is on line 4
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = &batchSink{size: 2, failAt: 2}
+	err = m.UnmarshalToSink(s)
+	var perr *csv.ParseError
+	var fe *FieldError
+	if !errors.As(err, &perr) || perr.Line != 4 || perr.Column != -1 || !errors.As(err, &fe) || fe.Err.Error() != "insert failed" {
+		t.Errorf("wrong error - want: insert failed on line 4, got: %v", err)
+	}
+	if s.flushed != 0 {
+		t.Errorf("sink flushed after error")
+	}
+}
+
+func TestUnmarshalToSinkDedup(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\na,2,true,2.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DedupBy("Field0", KeepLast); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UnmarshalToSink(&batchSink{size: 2}); err == nil {
+		t.Errorf("no error for KeepLast with a sink that cannot replace records")
+	}
+	s := &SliceSink{}
+	if err := m.UnmarshalToSink(s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Records) != 1 || s.Records[0].(TestStruct).Field1 != 2 {
+		t.Errorf("wrong records - want: last duplicate, got: %v", s.Records)
+	}
+}
@@ -0,0 +1,111 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+)
+
+var (
+	ErrAmbiguousDecimal = errors.New("decimal separator looks like thousands separator")
+)
+
+// thousandsPatterns match numbers with exactly three digits after a dot or comma, like 1.234 or
+// 12,345, by decimal separator. They are decimal numbers or integers with thousands separator.
+var thousandsPatterns = map[string]*regexp.Regexp{
+	".": regexp.MustCompile(`^[+-]?[0-9]{1,3}\.[0-9]{3}$`),
+	",": regexp.MustCompile(`^[+-]?[0-9]{1,3},[0-9]{3}$`),
+}
+
+// ColumnStats profiles the cells of a field, see WithColumnStats. Cells of a row after the first
+// cell that cannot be decoded are not counted.
+type ColumnStats struct {
+	Cells     int     // number of cells
+	Empty     int     // number of empty cells, before the default option is applied
+	Failures  int     // number of cells that could not be decoded or were zeroed, see ZeroOnError
+	Values    int     // number of numeric values, Min and Max are only set if it is > 0
+	Min       float64 // minimum value of a numeric field
+	Max       float64 // maximum value of a numeric field
+	Thousands int     // number of values of a float or scaled field with three digits after the decimal separator like 1.234, see ErrAmbiguousDecimal
+}
+
+// WithColumnStats profiles the cells of every decoded field except glob fields, the ColumnStats
+// are added to the Report per csv header name. Float and scaled fields whose values mostly have
+// exactly three digits after the decimal separator, the comma with the decimalcomma option, are
+// recorded as ErrAmbiguousDecimal warning in the Report: in a file with thousands separators 1.234
+// is 1234, not 1.234.
+func WithColumnStats() Option {
+	return func(m *Marshaler) {
+		m.columnStats = true
+	}
+}
+
+// observeCell adds a cell of the field to its ColumnStats, value is the converted cell.
+func (m *Marshaler) observeCell(fieldInfo fieldInfo, record []string, value interface{}, failed bool) {
+	stats, ok := m.report.ColumnStats[fieldInfo.headerName]
+	if !ok {
+		stats = &ColumnStats{}
+		m.report.ColumnStats[fieldInfo.headerName] = stats
+	}
+	stats.Cells++
+	positions := []int{fieldInfo.position}
+	if fieldInfo.isComposite() {
+		positions = fieldInfo.positions
+	}
+	empty := true
+	for _, position := range positions {
+		empty = empty && (position >= len(record) || len(record[position]) == 0)
+	}
+	if empty {
+		stats.Empty++
+	}
+	if failed {
+		stats.Failures++
+		return
+	}
+	if value == nil || !isNumeric(fieldInfo.kind) {
+		return
+	}
+	f := toFloat(fieldInfo, reflect.ValueOf(value))
+	if stats.Values == 0 {
+		stats.Min, stats.Max = f, f
+	}
+	stats.Min, stats.Max = math.Min(stats.Min, f), math.Max(stats.Max, f)
+	stats.Values++
+	if fieldInfo.isComposite() || fieldInfo.position >= len(record) || fieldInfo.kind != reflect.Float32 &&
+		fieldInfo.kind != reflect.Float64 && fieldInfo.scale == 0 {
+		return
+	}
+	separator := "."
+	if fieldInfo.scale > 0 {
+		separator = fieldInfo.decimalSeparator()
+	}
+	if thousandsPatterns[separator].MatchString(record[fieldInfo.position]) {
+		stats.Thousands++
+	}
+}
+
+// auditDecimals records an ErrAmbiguousDecimal warning for every column of the ColumnStats with
+// more than half of the values looking like numbers with thousands separator. With
+// WarningsAsErrors the first warning is returned.
+func (m *Marshaler) auditDecimals() error {
+	for _, fieldInfo := range m.fieldInfos {
+		stats, ok := m.report.ColumnStats[fieldInfo.headerName]
+		if !ok || stats.Thousands == 0 || 2*stats.Thousands <= stats.Values {
+			continue
+		}
+		if pe := m.warn(csv.ParseError{
+			StartLine: m.headerLine,
+			Line:      m.headerLine,
+			Column:    fieldInfo.position,
+			Err: fmt.Errorf("%w: %d of %d values of %s have three digits after the separator",
+				ErrAmbiguousDecimal, stats.Thousands, stats.Values, fieldInfo.headerName),
+		}); pe != nil {
+			return pe
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package csv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestColumnStats(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\n,-2,false,2.5\nc,x,true,1.5\nd,4,true,-0.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data), WithColumnStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); err == nil {
+		t.Fatal("no error for invalid cell, but it should")
+	}
+	want := map[string]ColumnStats{
+		"FIELD_0": {Cells: 4, Empty: 1},
+		"FIELD_1": {Cells: 4, Failures: 1, Values: 3, Min: -2, Max: 4},
+		"FIELD_2": {Cells: 3},
+		"FIELD_3": {Cells: 3, Values: 3, Min: -0.5, Max: 2.5},
+	}
+	stats := m.Report().ColumnStats
+	if len(stats) != len(want) {
+		t.Fatalf("wrong number of columns - want: %d, got: %d", len(want), len(stats))
+	}
+	for header, w := range want {
+		if got := stats[header]; got == nil || *got != w {
+			t.Errorf("%s: wrong stats - want: %+v, got: %+v", header, w, got)
+		}
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Unmarshal()
+	if m.Report().ColumnStats != nil {
+		t.Error("column stats without WithColumnStats")
+	}
+}
+
+type PriceStruct struct {
+	Name   string  `csv:"NAME"`
+	Price  float64 `csv:"PRICE"`
+	Amount int64   `csv:"AMOUNT,scale=3,decimalcomma"`
+}
+
+func TestColumnStatsAmbiguousDecimal(t *testing.T) {
+	tt := map[string]struct {
+		data    string
+		columns []int // columns with warning
+	}{
+		"thousands": {
+			data:    "NAME,PRICE,AMOUNT\na,1.234,\"1,5\"\nb,12.500,\"2,25\"\nc,1.5,\"3,0\"\n",
+			columns: []int{1},
+		},
+		"decimal comma": {
+			data:    "NAME,PRICE,AMOUNT\na,1.5,\"1,234\"\nb,2.5,\"12,500\"\nc,3.25,\"-1,000\"\n",
+			columns: []int{2},
+		},
+		"decimals": {
+			data: "NAME,PRICE,AMOUNT\na,1.234,\"1,5\"\nb,1.5,\"2,25\"\nc,2.25,\"3,0\"\n",
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(PriceStruct{}, strings.NewReader(tc.data), WithColumnStats())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.Unmarshal(); err != nil {
+				t.Fatal(err)
+			}
+			warnings := m.Report().Warnings
+			if len(warnings) != len(tc.columns) {
+				t.Fatalf("wrong warnings - want: %d, got: %v", len(tc.columns), warnings)
+			}
+			for i, w := range warnings {
+				if !errors.Is(w.Err, ErrAmbiguousDecimal) || w.Column != tc.columns[i] || w.Line != 1 {
+					t.Errorf("wrong warning - want: %s for column %d, got: %v", ErrAmbiguousDecimal, tc.columns[i], w)
+				}
+			}
+		})
+	}
+}
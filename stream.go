@@ -0,0 +1,118 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"time"
+)
+
+var (
+	ErrNothingToSkip  = errors.New("no broken record to skip")
+	ErrKeepLastStream = errors.New("DedupBy with KeepLast is not supported by Next")
+)
+
+// Next decodes the next record of the Reader, which is then available with Record. It returns
+// false at the end of the input or if a record cannot be read or decoded, Err returns the
+// error in the latter case. With Lazy, errors of single records are collected and returned by
+// Err at the end of the input instead. Duplicates of DedupBy are skipped like with KeepFirst,
+// KeepLast fails with ErrKeepLastStream because the first record was already returned.
+func (m *Marshaler) Next() bool {
+	if m.streamErr != nil {
+		return false
+	}
+	if m.streamStart.IsZero() {
+		if err := m.checkDedup(); err != nil {
+			m.streamErr = err
+			return false
+		}
+		if m.dedupField != "" && m.dedupKeep == KeepLast {
+			m.streamErr = ErrKeepLastStream
+			return false
+		}
+		m.streamSeen = map[string]bool{}
+		m.report = m.newReport()
+		m.streamStart = time.Now()
+		m.start = m.streamStart
+	}
+	m.current = nil
+	newRecord := func() interface{} { return reflect.New(reflect.TypeOf(m.endPointStruct)).Interface() }
+	for {
+		sPtr, err := m.next(m.Reader, newRecord, m.Lazy)
+		if err == io.EOF {
+			m.streamErr = err
+			if !m.skippable() {
+				m.Metrics.ObserveDuration(time.Since(m.streamStart))
+			}
+			return false
+		}
+		if sPtr != nil {
+			m.current = reflect.ValueOf(sPtr).Elem().Interface()
+		}
+		if err != nil {
+			m.streamErr = err
+			m.Metrics.ObserveDuration(time.Since(m.streamStart))
+			return false
+		}
+		if sPtr != nil && m.duplicate(sPtr) {
+			m.current = nil
+			continue
+		}
+		if sPtr != nil {
+			return true
+		}
+	}
+}
+
+// duplicate checks if Next returned a record with the DedupBy key of the decoded struct sPtr
+// before and counts it in the Report.
+func (m *Marshaler) duplicate(sPtr interface{}) bool {
+	if m.dedupField == "" {
+		return false
+	}
+	key := m.dedupKey(sPtr)
+	if m.streamSeen[key] {
+		m.report.Duplicates++
+		return true
+	}
+	m.streamSeen[key] = true
+	return false
+}
+
+// Record returns the endpoint struct decoded by the last call to Next.
+func (m *Marshaler) Record() interface{} {
+	return m.current
+}
+
+// Err returns the error that stopped Next. At the end of the input it returns the collected
+// errors of single records as ParseErrors or nil if there are none.
+func (m *Marshaler) Err() error {
+	if m.streamErr != nil && m.streamErr != io.EOF {
+		return m.streamErr
+	}
+	if len(m.errors) > 0 {
+		return m.errors
+	}
+	return nil
+}
+
+// Skip discards the broken record that stopped Next, so that the next call to Next continues
+// with the following record. Only errors of single data rows can be skipped, other errors are
+// returned as they are. Skipped records are not added to the errors returned by Err.
+func (m *Marshaler) Skip() error {
+	if m.streamErr == nil || m.streamErr == io.EOF {
+		return ErrNothingToSkip
+	}
+	if !m.skippable() {
+		return m.streamErr
+	}
+	m.streamErr = nil
+	return nil
+}
+
+// skippable checks if the error that stopped Next is an error of a single data row.
+func (m *Marshaler) skippable() bool {
+	var pe *csv.ParseError
+	return !m.isHeader() && m.line > 0 && errors.As(m.streamErr, &pe)
+}
@@ -0,0 +1,160 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNext(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	got := []string{}
+	for m.Next() {
+		got = append(got, m.Record().(TestStruct).Field0)
+	}
+	if strings.Join(got, ",") != "string1,string3" {
+		t.Errorf("wrong records - want: %s, got: %v", "string1,string3", got)
+	}
+	if errs, ok := m.Err().(ParseErrors); !ok || len(errs) != 1 || errs[0].Line != 3 {
+		t.Errorf("wrong error - want: one error in line 3, got: %v", m.Err())
+	}
+	if m.Record() != nil {
+		t.Errorf("wrong record at end of input - want: nil, got: %v", m.Record())
+	}
+}
+
+func TestSkip(t *testing.T) {
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(tooManyFields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if err := m.Skip(); err != ErrNothingToSkip {
+		t.Errorf("wrong error - want: %s, got: %v", ErrNothingToSkip, err)
+	}
+	got := []string{}
+	for {
+		for m.Next() {
+			got = append(got, m.Record().(TestStruct).Field0)
+		}
+		if m.Err() == nil {
+			break
+		}
+		if !errors.Is(m.Err(), csv.ErrFieldCount) {
+			t.Fatalf("wrong error - want: %s, got: %v", csv.ErrFieldCount, m.Err())
+		}
+		if err := m.Skip(); err != nil {
+			t.Fatalf("error in Skip: %s", err)
+		}
+	}
+	if strings.Join(got, ",") != "string2" {
+		t.Errorf("wrong records - want: %s, got: %v", "string2", got)
+	}
+
+	// decode errors stop Next without Lazy
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(wrongTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	for m.Next() {
+	}
+	if pe, ok := m.Err().(*csv.ParseError); !ok || pe.Line != 3 {
+		t.Fatalf("wrong error - want: ParseError in line 3, got: %v", m.Err())
+	}
+	if err := m.Skip(); err != nil || !m.Next() || m.Record().(TestStruct).Field0 != "string3" {
+		t.Errorf("wrong record after Skip - want: string3, got: %v, %v", m.Record(), err)
+	}
+
+	// header errors cannot be skipped
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(notEnoughHeaders))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	if m.Next() {
+		t.Fatal("Next returned true for incomplete header")
+	}
+	if err := m.Skip(); err == nil || err != m.Err() {
+		t.Errorf("wrong error - want: %v, got: %v", m.Err(), err)
+	}
+}
+
+func TestSkipConsecutive(t *testing.T) {
+	data := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring1;1;true;1.14\n" +
+		strings.Repeat("broken\n", 4) + "string2;2;true;2.14\n"
+	var skipTests = map[int]string{
+		3: "string1",
+		4: "string1,string2",
+	}
+	for maxSkips, want := range skipTests {
+		m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Reader.Comma = ';'
+		got := []string{}
+		skips := 0
+		for {
+			for m.Next() {
+				skips = 0
+				got = append(got, m.Record().(TestStruct).Field0)
+			}
+			if m.Err() == nil || skips == maxSkips {
+				break
+			}
+			if err := m.Skip(); err != nil {
+				t.Fatal(err)
+			}
+			skips++
+		}
+		if strings.Join(got, ",") != want {
+			t.Errorf("wrong records for %d skips - want: %s, got: %v", maxSkips, want, got)
+		}
+		if wantErr := maxSkips < 4; wantErr != (m.Err() != nil) {
+			t.Errorf("wrong error for %d skips - want error: %t, got: %v", maxSkips, wantErr, m.Err())
+		}
+	}
+}
+
+func TestNextDedupBy(t *testing.T) {
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,true,2.5\na,3,true,3.5\nc,4,true,4.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DedupBy("Field0", KeepFirst); err != nil {
+		t.Fatal(err)
+	}
+	got := []int{}
+	for m.Next() {
+		got = append(got, m.Record().(TestStruct).Field1)
+	}
+	if err := m.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong records - want: %v, got: %v", want, got)
+	}
+	if m.Report().Duplicates != 1 {
+		t.Errorf("wrong number of duplicates - want: %d, got: %d", 1, m.Report().Duplicates)
+	}
+
+	m, err = NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DedupBy("Field0", KeepLast); err != nil {
+		t.Fatal(err)
+	}
+	if m.Next() || !errors.Is(m.Err(), ErrKeepLastStream) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrKeepLastStream, m.Err())
+	}
+}
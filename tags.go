@@ -0,0 +1,152 @@
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// knownTagOptions are the supported csv tag options, flags have no value.
+var knownTagOptions = map[string]bool{
+	"default":       true, // value used for empty cells
+	"glob":          true, // bind a map or slice field to all columns matching the header name pattern
+	"ordered":       true, // order the values of a glob slice field by the matched part of the header
+	"upper":         true, // upper case string cells
+	"lower":         true, // lower case string cells
+	"title":         true, // title case string cells
+	"unquote":       true, // remove one pair of matching quotes around the cell, which are not csv quoting
+	"normalizetext": true, // normalize cells of encoding.TextUnmarshaler fields too
+	"enum":          true, // allowed values separated by |
+	"icase":         true, // match enum values case-insensitive
+	"match":         true, // regular expression the cell has to match
+	"min":           true, // minimum of a numeric field
+	"max":           true, // maximum of a numeric field
+	"scale":         true, // number of fractional digits of a decimal number stored in an integer field
+	"decimalcomma":  true, // fractional digits of scaled fields are separated by a comma
+	"readonly":      true, // decode the field but do not write it, e.g. a second field for the same column
+	"format":        true, // time layout of a time.Time field
+	"onerror":       true, // action for cells that cannot be converted: fail, zero or skiprow
+	"cols":          true, // one based first and last rune of the cell in fixed-width files, e.g. cols=1-10
+	"composite":     true, // decode the field from the joined cells of the columns of a header name like DATE+TIME
+	"join":          true, // separator of the cells of a composite field, a space by default
+	"boolnum":       true, // decode a bool field from an integer, zero is false and every other number true
+	"raw":           true, // keep the cell of a string field byte for byte, without escapes, normalization, defaults and converters
+	"pos":           true, // zero based column the field is bound to regardless of the header name, e.g. pos=4
+	"emptyquoted":   true, // write empty strings as "" and nil pointers and null driver.Valuer values as empty cell
+	"group":         true, // bind a slice of structs to repeated groups of numbered columns, e.g. MODIFIED_*
+}
+
+// tagOptions are the options of a csv tag, e.g. default=1 in `csv:"FIELD_1,default=1"`.
+// Options without value have an empty value.
+type tagOptions map[string]string
+
+// parseTag splits a csv tag in header name and options. Segments are separated by commas,
+// the first segment is the header name, all others are options of the form key or key=value.
+// A single trailing comma without options is allowed, e.g. `csv:"-,"` has the header name "-".
+// A backslash escapes a following comma or backslash, all other backslashes are kept,
+// e.g. `csv:"FIELD,default=a\\,b"` has the default value "a,b".
+// Option values enclosed in single quotes are taken literally without escaping, the closing
+// quote has to be followed by a comma or the end of the tag, e.g. `csv:"NR,match='^\\d{1,3}$'"`
+// has the pattern ^\d{1,3}$.
+func parseTag(tag string) (string, tagOptions, error) {
+	segments, err := splitTag(tag)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(segments) == 1 || len(segments) == 2 && segments[1] == "" {
+		return segments[0], nil, nil
+	}
+	options := tagOptions{}
+	for _, segment := range segments[1:] {
+		key, value := segment, ""
+		if i := strings.Index(segment, "="); i >= 0 {
+			key, value = segment[:i], segment[i+1:]
+		}
+		if !knownTagOptions[key] {
+			return "", nil, fmt.Errorf("unknown option: %q", key)
+		}
+		if _, ok := options[key]; ok {
+			return "", nil, fmt.Errorf("duplicate option: %q", key)
+		}
+		options[key] = value
+	}
+	return segments[0], options, nil
+}
+
+// splitTag splits a tag on unescaped commas and removes the escaping backslashes and the
+// quotes of quoted option values.
+func splitTag(tag string) ([]string, error) {
+	segments := []string{}
+	segment := []byte{}
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\'' && len(segments) > 0 && len(segment) > 0 && strings.IndexByte(string(segment), '=') == len(segment)-1:
+			end := closingQuote(tag, i+1)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted value: %s", tag[i:])
+			}
+			segment = append(segment, tag[i+1:end]...)
+			i = end
+		case tag[i] == '\\' && i+1 < len(tag) && (tag[i+1] == ',' || tag[i+1] == '\\'):
+			i++
+			segment = append(segment, tag[i])
+		case tag[i] == ',':
+			segments = append(segments, string(segment))
+			segment = segment[:0]
+		default:
+			segment = append(segment, tag[i])
+		}
+	}
+	return append(segments, string(segment)), nil
+}
+
+// closingQuote returns the index of the first quote at or after start that is followed by
+// a comma or the end of the tag, -1 if there is none.
+func closingQuote(tag string, start int) int {
+	for i := start; i < len(tag); i++ {
+		if tag[i] == '\'' && (i+1 == len(tag) || tag[i+1] == ',') {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateDefault converts the value of the default option once like an empty cell, so an invalid
+// default fails when the fields are created instead of at the first empty cell. Fields without
+// built-in conversion are only checked per cell, their converters may be registered later.
+func validateDefault(fieldInfo fieldInfo) error {
+	value, ok := fieldInfo.options["default"]
+	if !ok || fieldInfo.isGroup() {
+		return nil
+	}
+	value = fieldInfo.normalize(value)
+	if fieldInfo.isGlob() {
+		fieldInfo.typ = fieldInfo.typ.Elem()
+		fieldInfo.kind = fieldInfo.typ.Kind()
+	}
+	if !fieldInfo.hasBuiltinConversion() {
+		return nil
+	}
+	if _, err := (&Marshaler{}).convert(fieldInfo, value); err != nil {
+		return fmt.Errorf("invalid default value %q: %s", value, err)
+	}
+	return nil
+}
+
+// hasBuiltinConversion checks if cells of the field are converted without a converter of
+// RegisterConverter or RegisterType.
+func (fieldInfo fieldInfo) hasBuiltinConversion() bool {
+	if _, ok := builtinConverters[fieldInfo.typ]; ok || len(fieldInfo.layout) > 0 {
+		return true
+	}
+	if reflect.PtrTo(fieldInfo.typ).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch fieldInfo.kind {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
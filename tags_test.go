@@ -0,0 +1,255 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	var parseTagTests = []struct {
+		tag     string
+		name    string
+		options tagOptions
+		err     bool
+	}{
+		{`FIELD_0`, "FIELD_0", nil, false},
+		{`-`, "-", nil, false},
+		{`FIELD 0`, "FIELD 0", nil, false},
+		{`FIELD_0,default=1`, "FIELD_0", tagOptions{"default": "1"}, false},
+		{`FIELD_0,default=`, "FIELD_0", tagOptions{"default": ""}, false},
+		{`FIELD_0,default`, "FIELD_0", tagOptions{"default": ""}, false},
+		{`FIELD_0,default=a=b`, "FIELD_0", tagOptions{"default": "a=b"}, false},
+		{`FIELD_0,default=a\,b`, "FIELD_0", tagOptions{"default": "a,b"}, false},
+		{`FIELD_0,default=a\\`, "FIELD_0", tagOptions{"default": `a\`}, false},
+		{`FIELD_0,default=a\\,b`, "", nil, true}, // escaped backslash followed by the unknown option b
+		{`FIELD_0,default=\d`, "FIELD_0", tagOptions{"default": `\d`}, false},
+		{`FIELD_0,default=\`, "FIELD_0", tagOptions{"default": `\`}, false},
+		{`FIELD\,0,default=1`, "FIELD,0", tagOptions{"default": "1"}, false},
+		{`,default=1`, "", tagOptions{"default": "1"}, false},
+		{`FIELD_0,`, "FIELD_0", nil, false},
+		{`-,`, "-", nil, false},
+		{`FIELD_0,,`, "", nil, true},
+		{`FIELD_0,unknown`, "", nil, true},
+		{`FIELD_0,unknown=1`, "", nil, true},
+		{`FIELD_0,default=1,default=2`, "", nil, true},
+		{`FIELD_0,=1`, "", nil, true},
+		{`FIELD_0,match='^\d{1,3}$'`, "FIELD_0", tagOptions{"match": `^\d{1,3}$`}, false},
+		{`FIELD_0,match='a\\,b',default=1`, "FIELD_0", tagOptions{"match": `a\\,b`, "default": "1"}, false},
+		{`FIELD_0,match='it's',default='x'`, "FIELD_0", tagOptions{"match": "it's", "default": "x"}, false},
+		{`FIELD_0,default=''`, "FIELD_0", tagOptions{"default": ""}, false},
+		{`FIELD_0,default='a,b`, "", nil, true},   // unterminated quote
+		{`FIELD_0,default=a'b,c'`, "", nil, true}, // quotes inside a value are literal, c' is an unknown option
+		{`'FIELD,0'`, "", nil, true},              // header names are never quoted
+		{`FIELD_0,default='a'b'`, "FIELD_0", tagOptions{"default": "a'b"}, false},
+	}
+	for _, test := range parseTagTests {
+		name, options, err := parseTag(test.tag)
+		if (err != nil) != test.err {
+			t.Errorf("wrong error for tag %s - want error: %t, got: %v", test.tag, test.err, err)
+			continue
+		}
+		if name != test.name || !reflect.DeepEqual(options, test.options) {
+			t.Errorf("wrong result for tag %s - want: %q %v, got: %q %v", test.tag, test.name, test.options, name, options)
+		}
+	}
+}
+
+func TestCsvHeadersTagOptions(t *testing.T) {
+	type OptionStruct struct {
+		Field0 string `csv:"FIELD_0,default=a\\,b"`
+		Field1 int    `csv:",default=1"`
+		Field2 bool   `csv:"-"`
+	}
+	generatedFieldInfos, err := createFieldInfos(OptionStruct{})
+	if err != nil {
+		t.Fatalf("error occured in TestCsvHeadersTagOptions: %s", err)
+	}
+	if len(generatedFieldInfos) != 2 {
+		t.Fatalf("wrong number of haeders generated: %v", generatedFieldInfos)
+	}
+	if fi := generatedFieldInfos[0]; fi.headerName != "FIELD_0" || fi.options["default"] != "a,b" {
+		t.Errorf("wrong fieldInfo for tag with escaped comma: %v", fi)
+	}
+	if fi := generatedFieldInfos[1]; fi.headerName != "Field1" || fi.options["default"] != "1" {
+		t.Errorf("wrong fieldInfo for tag with empty name: %v", fi)
+	}
+
+	type UnknownOptionStruct struct {
+		Field0 string `csv:"FIELD_0,unknown"`
+	}
+	if _, err := createFieldInfos(UnknownOptionStruct{}); err == nil {
+		t.Error("no error for unknown tag option, but it should")
+	}
+
+	data := `FIELD_0;Field1
+;
+b;2`
+	m, err := NewMarshaler(OptionStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatalf("error in UnmarshalCSV: %s", err)
+	}
+	want := []interface{}{OptionStruct{Field0: "a,b", Field1: 1}, OptionStruct{Field0: "b", Field1: 2}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+}
+
+func TestTagGrammar(t *testing.T) {
+	tt := map[string]struct {
+		header  string // expected header name, empty for ignored fields
+		options tagOptions
+		err     bool
+	}{
+		`FIELD`:               {header: "FIELD"},
+		`FIELD,`:              {header: "FIELD"},
+		`FIELD,default=1`:     {header: "FIELD", options: tagOptions{"default": "1"}},
+		`,default=1`:          {header: "Field", options: tagOptions{"default": "1"}},
+		`,`:                   {header: "Field"},
+		`,upper,default=a`:    {header: "Field", options: tagOptions{"upper": "", "default": "a"}},
+		`-`:                   {},
+		`-,`:                  {header: "-"},
+		`-,default=1`:         {header: "-", options: tagOptions{"default": "1"}},
+		`FIELD-1`:             {header: "FIELD-1"},
+		`--`:                  {header: "--"},
+		` -`:                  {header: " -"},
+		`FIELD,,`:             {err: true},
+		`,unknown`:            {err: true},
+		`-,unknown`:           {err: true},
+		`FIELD,default,upper`: {header: "FIELD", options: tagOptions{"default": "", "upper": ""}},
+	}
+	for tag, tc := range tt {
+		typ := reflect.StructOf([]reflect.StructField{{Name: "Field", Type: reflect.TypeOf(""), Tag: reflect.StructTag(`csv:"` + tag + `"`)}})
+		fieldInfos, err := createFieldInfos(reflect.New(typ).Elem().Interface())
+		if (err != nil) != tc.err {
+			t.Errorf("wrong error for tag %q - want error: %t, got: %v", tag, tc.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if tc.header == "" {
+			if len(fieldInfos) != 0 {
+				t.Errorf("field with tag %q not ignored: %v", tag, fieldInfos)
+			}
+			continue
+		}
+		if len(fieldInfos) != 1 || fieldInfos[0].headerName != tc.header || !reflect.DeepEqual(fieldInfos[0].options, tc.options) {
+			t.Errorf("wrong header for tag %q - want: %q %v, got: %v", tag, tc.header, tc.options, fieldInfos)
+		}
+	}
+}
+
+func TestHyphenatedHeaderRequired(t *testing.T) {
+	type Hyphenated struct {
+		ID     string `csv:"ID"`
+		FieldA string `csv:"FIELD-A"`
+	}
+	m, err := NewMarshaler(Hyphenated{}, strings.NewReader("ID\n1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); !errors.Is(err, ErrHeaderNotComplete) {
+		t.Errorf("wrong error - want: %s, got: %v", ErrHeaderNotComplete, err)
+	}
+
+	m, err = NewMarshaler(Hyphenated{}, strings.NewReader("ID,FIELD-A\n1,a\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result[0].(Hyphenated).FieldA; got != "a" {
+		t.Errorf("wrong FieldA - want: %s, got: %s", "a", got)
+	}
+}
+
+func TestTagOptionKinds(t *testing.T) {
+	kinds := map[string]reflect.Type{
+		"string": reflect.TypeOf(""),
+		"int":    reflect.TypeOf(0),
+		"uint":   reflect.TypeOf(uint(0)),
+		"float":  reflect.TypeOf(0.0),
+		"bool":   reflect.TypeOf(false),
+		"time":   timeType, // an encoding.TextUnmarshaler
+	}
+	all := []string{"string", "int", "uint", "float", "bool", "time"}
+	// valid lists the kinds each tag is valid for, all other kinds are an error
+	tt := map[string]struct {
+		tag   string
+		valid []string
+	}{
+		"default":       {tag: "F,default=1", valid: []string{"string", "int", "uint", "float", "bool"}},
+		"default text":  {tag: "F,default=abc", valid: []string{"string"}},
+		"default time":  {tag: "F,default=2024-01-02T00:00:00Z", valid: []string{"string", "time"}},
+		"glob":          {tag: "F_*,glob"},
+		"ordered":       {tag: "F,ordered"},
+		"upper":         {tag: "F,upper", valid: []string{"string"}},
+		"lower":         {tag: "F,lower", valid: []string{"string"}},
+		"title":         {tag: "F,title", valid: []string{"string"}},
+		"unquote":       {tag: "F,unquote", valid: all},
+		"normalizetext": {tag: "F,normalizetext"},
+		"upper text":    {tag: "F,upper,normalizetext", valid: []string{"string", "time"}},
+		"enum":          {tag: "F,enum=1|2", valid: []string{"string", "int"}},
+		"icase":         {tag: "F,icase"},
+		"enum icase":    {tag: "F,enum=a|b,icase", valid: []string{"string"}},
+		"match":         {tag: "F,match=^1", valid: all},
+		"min":           {tag: "F,min=1", valid: []string{"int", "uint", "float"}},
+		"max":           {tag: "F,max=1", valid: []string{"int", "uint", "float"}},
+		"scale":         {tag: "F,scale=2", valid: []string{"int"}},
+		"decimalcomma":  {tag: "F,decimalcomma"},
+		"scale comma":   {tag: "F,scale=2,decimalcomma", valid: []string{"int"}},
+		"readonly":      {tag: "F,readonly", valid: all},
+		"format":        {tag: "F,format=2006-01-02", valid: []string{"time"}},
+		"onerror":       {tag: "F,onerror=zero", valid: all},
+		"cols":          {tag: "F,cols=1-3", valid: all},
+		"join":          {tag: "F,join=-"},
+		"composite":     {tag: "A+B,composite,join=-", valid: all},
+		"composite one": {tag: "F,composite"},
+		"plus name":     {tag: "C++", valid: all},
+		"boolnum":       {tag: "F,boolnum", valid: []string{"bool"}},
+		"raw":           {tag: "F,raw", valid: []string{"string"}},
+		"pos":           {tag: "F,pos=4", valid: all},
+		"emptyquoted":   {tag: "F,emptyquoted", valid: []string{"string"}},
+		"group":         {tag: "F_*,group"},
+	}
+	covered := map[string]bool{}
+	for name, tc := range tt {
+		_, options, err := parseTag(tc.tag)
+		if err != nil {
+			t.Fatalf("invalid tag %s: %s", tc.tag, err)
+		}
+		for option := range options {
+			covered[option] = true
+		}
+		valid := map[string]bool{}
+		for _, kind := range tc.valid {
+			valid[kind] = true
+		}
+		for kind, typ := range kinds {
+			s := reflect.New(reflect.StructOf([]reflect.StructField{
+				{Name: "F", Type: typ, Tag: reflect.StructTag(`csv:"` + tc.tag + `"`)},
+			})).Elem().Interface()
+			_, err := NewSchema(s)
+			if valid[kind] && err != nil {
+				t.Errorf("unexpected error for %s on %s: %s", name, kind, err)
+			}
+			if !valid[kind] && (err == nil || !strings.Contains(err.Error(), "field F")) {
+				t.Errorf("wrong error for %s on %s - want: error naming the field, got: %v", name, kind, err)
+			}
+		}
+	}
+	for option := range knownTagOptions {
+		if !covered[option] {
+			t.Errorf("option %s not covered", option)
+		}
+	}
+}
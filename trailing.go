@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultTrailingColumnRows is the default of Marshaler.TrailingColumnRows.
+const defaultTrailingColumnRows = 100
+
+var (
+	ErrTrailingEmptyColumn = errors.New("trailing empty column")
+)
+
+// detectTrailingColumn reads the header and the first TrailingColumnRows data rows without
+// consuming them and checks if their last column is always empty, e.g. because every line ends
+// with a delimiter. The header may have an empty name for it or not. It returns the width of the
+// records with the empty column, 0 if there is none.
+func (m *Marshaler) detectTrailingColumn() int {
+	r := m.replay()
+	defer m.source.rewind()
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil || len(header) == 0 {
+		return 0
+	}
+	width := len(header) + 1
+	if isBlank(header[len(header)-1]) {
+		width = len(header)
+	}
+	n := m.TrailingColumnRows
+	if n <= 0 {
+		n = defaultTrailingColumnRows
+	}
+	rows := 0
+	for ; rows < n; rows++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(record) != width || !isBlank(record[width-1]) {
+			return 0
+		}
+	}
+	if rows == 0 {
+		return 0
+	}
+	return width
+}
+
+// trailingColumn removes the empty last cell of records with the width of the trailing empty
+// column found by detectTrailingColumn. The csv.ErrFieldCount of a data row that has the width
+// of the header without the cell is cleared.
+func (m *Marshaler) trailingColumn(record []string, err error) ([]string, error) {
+	if m.trailingWidth == 0 || len(record) != m.trailingWidth || !isBlank(record[len(record)-1]) {
+		return record, err
+	}
+	record = record[:len(record)-1]
+	if pe, ok := err.(*csv.ParseError); ok && pe.Err == csv.ErrFieldCount && !m.isHeader() && len(record) == len(m.header) {
+		err = nil
+	}
+	return record, err
+}
+
+// trailingColumnWarning records the trailing empty column as warning in the Report unless it is
+// dropped silently with DropTrailingEmptyColumn. With WarningsAsErrors the warning is returned.
+func (m *Marshaler) trailingColumnWarning() error {
+	if m.trailingWidth == 0 || m.DropTrailingEmptyColumn {
+		return nil
+	}
+	if pe := m.warn(csv.ParseError{
+		StartLine: m.headerLine,
+		Line:      m.headerLine,
+		Column:    len(m.header),
+		Err:       fmt.Errorf("%w: column %d dropped", ErrTrailingEmptyColumn, len(m.header)+1),
+	}); pe != nil {
+		return pe
+	}
+	return nil
+}
+
+// isBlank reports if s contains only white space.
+func isBlank(s string) bool {
+	return len(strings.TrimSpace(s)) == 0
+}
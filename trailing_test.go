@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrailingEmptyColumn(t *testing.T) {
+	want := []interface{}{
+		TestStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5},
+		TestStruct{Field0: "b", Field1: 2, Field2: false, Field3: 2.5},
+	}
+	tt := map[string]struct {
+		data     string
+		drop     bool
+		warnings int
+	}{
+		"empty header name": {
+			data:     "FIELD_0;FIELD_1;FIELD_2;FIELD_3;\na;1;true;1.5;\nb;2;false;2.5; \n",
+			warnings: 1,
+		},
+		"without header name": {
+			data:     "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na;1;true;1.5;\nb;2;false;2.5;\n",
+			warnings: 1,
+		},
+		"dropped silently": {
+			data: "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na;1;true;1.5;\nb;2;false;2.5;\n",
+			drop: true,
+		},
+		"no trailing column": {
+			data: "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na;1;true;1.5\nb;2;false;2.5\n",
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.Reader.Comma = ';'
+			m.DetectTrailingEmptyColumn = !tc.drop
+			m.DropTrailingEmptyColumn = tc.drop
+			result, err := m.Unmarshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, want) {
+				t.Errorf("wrong result - want: %v, got: %v", want, result)
+			}
+			warnings := m.Report().Warnings
+			if len(warnings) != tc.warnings {
+				t.Fatalf("wrong warnings - want: %d, got: %v", tc.warnings, warnings)
+			}
+			if tc.warnings > 0 && (!errors.Is(warnings[0].Err, ErrTrailingEmptyColumn) || warnings[0].Line != 1 || warnings[0].Column != 4) {
+				t.Errorf("wrong warning - want: %s on line 1 column 4, got: %v", ErrTrailingEmptyColumn, warnings[0])
+			}
+			if len(m.Headers()) != 4 {
+				t.Errorf("wrong headers - want: 4, got: %v", m.Headers())
+			}
+		})
+	}
+}
+
+func TestTrailingEmptyColumnNotUniform(t *testing.T) {
+	// a filled last cell in the checked rows disables the detection
+	data := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na;1;true;1.5;\nb;2;false;2.5;x\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	m.DetectTrailingEmptyColumn = true
+	_, err = m.Unmarshal()
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 2 {
+		t.Fatalf("wrong errors - want: 2 ParseErrors, got: %v", err)
+	}
+
+	// rows after TrailingColumnRows are not checked, an extra cell there fails
+	data = "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na;1;true;1.5;\nb;2;false;2.5;x\n"
+	if m, err = NewMarshaler(TestStruct{}, strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	m.Lazy = true
+	m.DropTrailingEmptyColumn = true
+	m.TrailingColumnRows = 1
+	result, err := m.Unmarshal()
+	pe, ok = err.(ParseErrors)
+	if !ok || len(pe) != 1 || pe[0].Line != 3 || len(result) != 1 {
+		t.Fatalf("wrong errors - want: 1 ParseError on line 3, got: %v", err)
+	}
+}
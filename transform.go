@@ -0,0 +1,50 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Transform streams the records of r decoded to the endpoint struct in through fn and writes the
+// returned endpoint structs of type out to w, one record at a time. The Marshaler is configured
+// with opts, the Writer uses the same Comma. If fn returns nil, the record is dropped. An error of
+// fn rejects the record with the input line and column -1 like a decode error: it stops the
+// transform, with the option of a Lazy Marshaler it is collected and returned as ParseErrors at
+// the end. Everything written before an error is flushed to w.
+func Transform(r io.Reader, w io.Writer, in interface{}, out interface{}, fn func(in interface{}) (interface{}, error), opts ...Option) (Report, error) {
+	m, err := NewMarshaler(in, r, opts...)
+	if err != nil {
+		return Report{}, err
+	}
+	writer, err := NewWriter(out, w)
+	if err != nil {
+		return Report{}, err
+	}
+	m.resolveDelimiter()
+	writer.Writer.Comma = m.Reader.Comma
+	for m.Next() {
+		result, err := fn(m.Record())
+		if err != nil {
+			pe := &csv.ParseError{Line: m.recordLine, Column: -1, Err: &FieldError{Err: err}}
+			m.labelError(pe)
+			m.report.FailedRows++
+			if !m.Lazy {
+				writer.Close()
+				return m.Report(), pe
+			}
+			m.errors = append(m.errors, *pe)
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		if err := writer.Write(result); err != nil {
+			writer.Flush()
+			return m.Report(), err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return m.Report(), err
+	}
+	return m.Report(), m.Err()
+}
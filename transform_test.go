@@ -0,0 +1,102 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type CanonicalStruct struct {
+	Name  string  `csv:"NAME"`
+	Total float64 `csv:"TOTAL"`
+}
+
+func TestTransform(t *testing.T) {
+	data := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na;1;true;1.5\nb;x;true;2.5\nc;3;false;3.5\nd;4;true;-1\ne;5;true;5.5\n"
+	fn := func(in interface{}) (interface{}, error) {
+		s := in.(TestStruct)
+		if !s.Field2 {
+			return nil, nil
+		}
+		if s.Field3 < 0 {
+			return nil, errors.New("negative total")
+		}
+		return CanonicalStruct{Name: strings.ToUpper(s.Field0), Total: float64(s.Field1) * s.Field3}, nil
+	}
+	lazy := func(m *Marshaler) {
+		m.Lazy = true
+		m.Reader.Comma = ';'
+	}
+
+	buf := &bytes.Buffer{}
+	report, err := Transform(strings.NewReader(data), buf, TestStruct{}, CanonicalStruct{}, fn, lazy)
+	if want := "NAME;TOTAL\nA;1.5\nE;27.5\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("wrong errors - want: 2 ParseErrors, got: %v", err)
+	}
+	if errs[0].Line != 3 || errs[1].Line != 5 || errs[1].Column != -1 {
+		t.Errorf("wrong errors - want: lines 3 and 5, got: %v", errs)
+	}
+	if report.Rows != 5 || report.FailedRows != 2 {
+		t.Errorf("wrong report - want: 5 rows and 2 failed, got: %d and %d", report.Rows, report.FailedRows)
+	}
+
+	// without Lazy the first error stops the transform, the records before are written
+	buf.Reset()
+	comma := func(m *Marshaler) { m.Reader.Comma = ';' }
+	_, err = Transform(strings.NewReader(strings.Replace(data, "b;x", "b;2", 1)), buf, TestStruct{}, CanonicalStruct{}, fn, comma, WithSource("in.csv"))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Source != "in.csv" || fe.Err.Error() != "negative total" {
+		t.Errorf("wrong error - want: negative total from in.csv, got: %v", err)
+	}
+	if want := "NAME;TOTAL\nA;1.5\nB;5\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+}
+
+type limitedName string
+
+func (n limitedName) MarshalCSV() (string, error) {
+	if len(n) > 1 {
+		return "", errors.New("name too long")
+	}
+	return string(n), nil
+}
+
+func TestTransformWriteError(t *testing.T) {
+	type limited struct {
+		Name limitedName `csv:"NAME"`
+	}
+	data := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5\nb,2,true,2.5\ncc,3,true,3.5\nd,4,true,4.5\n"
+	fn := func(in interface{}) (interface{}, error) {
+		return limited{Name: limitedName(in.(TestStruct).Field0)}, nil
+	}
+	buf := &bytes.Buffer{}
+	_, err := Transform(strings.NewReader(data), buf, TestStruct{}, limited{}, fn)
+	var we *WriteError
+	if !errors.As(err, &we) || we.Record != 2 {
+		t.Errorf("wrong error - want: WriteError of record 2, got: %v", err)
+	}
+	if want := "NAME\na\nb\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+}
+
+func TestTransformDelimiter(t *testing.T) {
+	data := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\na;2;true;1.5\n"
+	fn := func(in interface{}) (interface{}, error) {
+		s := in.(TestStruct)
+		return CanonicalStruct{Name: s.Field0, Total: float64(s.Field1) * s.Field3}, nil
+	}
+	buf := &bytes.Buffer{}
+	if _, err := Transform(strings.NewReader(data), buf, TestStruct{}, CanonicalStruct{}, fn, WithDelimiter(";")); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME;TOTAL\na;3\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+}
@@ -0,0 +1,85 @@
+package csv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrInvalidTSVCell = errors.New("cell contains tab or line break")
+)
+
+var (
+	tsvEscaper   = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+	tsvUnescaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\r`, "\r")
+)
+
+// NewTSVMarshaler returns a new Marshaler for tab-separated files configured with opts. Cells are
+// separated by tabs and taken literally, quotes have no special meaning and records end at the
+// end of the line. With BackslashEscapes the escapes \t, \n, \r and \\ in cells are decoded.
+func NewTSVMarshaler(endPointStruct interface{}, r io.Reader, opts ...Option) (*Marshaler, error) {
+	m, err := NewMarshaler(endPointStruct, r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.Reader.Comma = '\t'
+	m.tsv = true
+	return m, nil
+}
+
+// NewTSVWriter returns a new Writer for tab-separated files. Cells are written without quotes,
+// cells with tabs or line breaks fail with ErrInvalidTSVCell unless BackslashEscapes is set, which
+// writes them as \t, \n and \r and a backslash as \\. Writer.UseCRLF is ignored. A record of one
+// empty cell is an empty line, which is skipped when reading.
+func NewTSVWriter(endPointStruct interface{}, w io.Writer) (*Writer, error) {
+	tw, err := NewWriter(endPointStruct, w)
+	if err != nil {
+		return nil, err
+	}
+	tw.useTSV()
+	return tw, nil
+}
+
+// useTSV switches the Writer to tab-separated output.
+func (w *Writer) useTSV() {
+	w.Writer.Comma = '\t'
+	w.tsv = bufio.NewWriter(w.out)
+}
+
+// unescape decodes the backslash escapes of cell, unknown escapes are kept.
+func unescape(cell string) string {
+	if strings.IndexByte(cell, '\\') < 0 {
+		return cell
+	}
+	return tsvUnescaper.Replace(cell)
+}
+
+// writeRecord writes record with the csv.Writer, with writeQuoted if a field has the emptyquoted
+// option, or tab-separated for a Writer of NewTSVWriter.
+func (w *Writer) writeRecord(record []string, quoted []bool) error {
+	if w.tsv == nil && len(record) == 1 && record[0] == "" {
+		if err := w.quoteEmptyRecord(); err != nil {
+			return err
+		}
+		quoted = []bool{true}
+	}
+	if w.quoting != nil {
+		return w.writeQuoted(record, quoted)
+	}
+	if w.tsv == nil {
+		return w.Writer.Write(record)
+	}
+	for i, cell := range record {
+		if w.BackslashEscapes {
+			record[i] = tsvEscaper.Replace(cell)
+		} else if strings.ContainsAny(cell, "\t\r\n") {
+			return &WriteError{Record: w.records, Field: w.fieldInfos[i].fieldName, Err: ErrInvalidTSVCell}
+		}
+	}
+	if _, err := w.tsv.WriteString(strings.Join(record, "\t")); err != nil {
+		return err
+	}
+	return w.tsv.WriteByte('\n')
+}
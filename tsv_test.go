@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTSVMarshaler(t *testing.T) {
+	tt := map[string]struct {
+		data             string
+		backslashEscapes bool
+		want             []interface{}
+	}{
+		"literal quotes": {
+			data: "FIELD_0\tFIELD_1\tFIELD_2\tFIELD_3\n\"a, \"b\"\t1\ttrue\t1.5\n",
+			want: []interface{}{TestStruct{Field0: `"a, "b"`, Field1: 1, Field2: true, Field3: 1.5}},
+		},
+		"escapes kept": {
+			data: "FIELD_0\tFIELD_1\tFIELD_2\tFIELD_3\na\\tb\t1\ttrue\t1.5\n",
+			want: []interface{}{TestStruct{Field0: `a\tb`, Field1: 1, Field2: true, Field3: 1.5}},
+		},
+		"escapes decoded": {
+			data:             "FIELD_0\tFIELD_1\tFIELD_2\tFIELD_3\r\na\\tb\\nc\\\\n\\x\t1\ttrue\t1.5\r\n",
+			backslashEscapes: true,
+			want:             []interface{}{TestStruct{Field0: "a\tb\nc\\n\\x", Field1: 1, Field2: true, Field3: 1.5}},
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewTSVMarshaler(TestStruct{}, strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.BackslashEscapes = tc.backslashEscapes
+			result, err := m.Unmarshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tc.want) {
+				t.Errorf("wrong result - want: %v, got: %v", tc.want, result)
+			}
+		})
+	}
+}
+
+func TestTSVWriter(t *testing.T) {
+	s := TestStruct{Field0: "a\tb\\c\n\"d\"", Field1: 1, Field2: true, Field3: 1.5}
+	buf := &bytes.Buffer{}
+	w, err := NewTSVWriter(TestStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.BackslashEscapes = true
+	if err := w.Marshal([]interface{}{s}); err != nil {
+		t.Fatal(err)
+	}
+	want := "FIELD_0\tFIELD_1\tFIELD_2\tFIELD_3\na\\tb\\\\c\\n\"d\"\t1\ttrue\t1.5\n"
+	if buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+
+	// round trip
+	m, err := NewTSVMarshaler(TestStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.BackslashEscapes = true
+	result, err := m.Unmarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{s}; !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong round trip - want: %v, got: %v", want, result)
+	}
+
+	// without escapes tabs and line breaks cannot be written
+	if w, err = NewTSVWriter(TestStruct{}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	err = w.Marshal([]interface{}{s})
+	var we *WriteError
+	if !errors.As(err, &we) || !errors.Is(err, ErrInvalidTSVCell) || we.Field != "Field0" {
+		t.Errorf("wrong error - want: %s for Field0, got: %v", ErrInvalidTSVCell, err)
+	}
+}
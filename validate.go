@@ -0,0 +1,162 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNotInEnum   = errors.New("value not in enum")
+	ErrNoMatch     = errors.New("value does not match pattern")
+	ErrOutOfBounds = errors.New("value out of bounds")
+)
+
+// enum is the set of values allowed by the enum tag option, e.g. `csv:"STATUS,enum=active|inactive"`.
+// Strings match case-sensitive unless the icase option is set, integers are compared numerically.
+type enum struct {
+	values []string
+	ints   map[int64]bool // allowed values of integer fields
+	icase  bool
+}
+
+// parseEnum parses the enum option for a field of type typ, it returns nil without enum option.
+func parseEnum(typ reflect.Type, options tagOptions) (*enum, error) {
+	list, ok := options["enum"]
+	_, icase := options["icase"]
+	if !ok {
+		if icase {
+			return nil, fmt.Errorf("option icase requires the enum option")
+		}
+		return nil, nil
+	}
+	e := &enum{values: strings.Split(list, "|"), icase: icase}
+	switch typ.Kind() {
+	case reflect.String:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if icase {
+			return nil, fmt.Errorf("option icase requires a string field, got: %s", typ)
+		}
+		e.ints = map[int64]bool{}
+		for _, value := range e.values {
+			i, err := strconv.ParseInt(value, 10, typ.Bits())
+			if err != nil {
+				return nil, fmt.Errorf("invalid enum value %q: %s", value, err)
+			}
+			e.ints[i] = true
+		}
+	default:
+		return nil, fmt.Errorf("option enum requires a string or integer field, got: %s", typ)
+	}
+	return e, nil
+}
+
+// check checks if the converted value is allowed.
+func (e *enum) check(value interface{}) error {
+	v := reflect.ValueOf(value)
+	if e.ints != nil {
+		if e.ints[v.Int()] {
+			return nil
+		}
+	} else {
+		for _, allowed := range e.values {
+			if v.String() == allowed || e.icase && strings.EqualFold(v.String(), allowed) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %v, allowed: %s", ErrNotInEnum, value, strings.Join(e.values, "|"))
+}
+
+// parseMatch compiles the pattern of the match option, it returns nil without match option.
+func parseMatch(options tagOptions) (*regexp.Regexp, error) {
+	pattern, ok := options["match"]
+	if !ok {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match pattern: %s", err)
+	}
+	return re, nil
+}
+
+// matchCell checks the cell against the pattern of the match option before conversion.
+func (fieldInfo fieldInfo) matchCell(cell string) error {
+	if fieldInfo.match == nil || fieldInfo.match.MatchString(cell) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q, pattern: %s", ErrNoMatch, cell, fieldInfo.match)
+}
+
+// bound is the limit of a min or max tag option, e.g. `csv:"QTY,min=0,max=10000"`, parsed
+// according to the kind of the field.
+type bound struct {
+	option string // min or max
+	text   string
+	i      int64
+	u      uint64
+	f      float64
+}
+
+// parseBounds parses the min and max options for a field of type typ, bounds of scaled
+// fields are decimal numbers.
+func parseBounds(typ reflect.Type, options tagOptions, scale int) ([]bound, error) {
+	var bounds []bound
+	for _, option := range []string{"min", "max"} {
+		text, ok := options[option]
+		if !ok {
+			continue
+		}
+		b := bound{option: option, text: text}
+		var err error
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if scale > 0 {
+				b.i, err = fieldInfo{typ: typ, options: options, scale: scale}.parseScaled(text)
+			} else {
+				b.i, err = strconv.ParseInt(text, 10, typ.Bits())
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			b.u, err = strconv.ParseUint(text, 10, typ.Bits())
+		case reflect.Float32, reflect.Float64:
+			b.f, err = strconv.ParseFloat(text, typ.Bits())
+		default:
+			return nil, fmt.Errorf("option %s requires a numeric field, got: %s", option, typ)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %s", option, text, err)
+		}
+		bounds = append(bounds, b)
+	}
+	if len(bounds) == 2 {
+		min, max := bounds[0], bounds[1]
+		if min.i > max.i || min.u > max.u || min.f > max.f {
+			return nil, fmt.Errorf("min %s is greater than max %s", min.text, max.text)
+		}
+	}
+	return bounds, nil
+}
+
+// checkBounds checks the converted value against the bounds of the field.
+func (fieldInfo fieldInfo) checkBounds(value interface{}) error {
+	v := reflect.ValueOf(value)
+	for _, b := range fieldInfo.bounds {
+		var below, above bool
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			below, above = v.Int() < b.i, v.Int() > b.i
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			below, above = v.Uint() < b.u, v.Uint() > b.u
+		default:
+			below, above = v.Float() < b.f, v.Float() > b.f
+		}
+		if b.option == "min" && below || b.option == "max" && above {
+			return fmt.Errorf("%w: %v, %s: %s", ErrOutOfBounds, value, b.option, b.text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type EnumStruct struct {
+	Status   string         `csv:"STATUS,enum=active|inactive|pending"`
+	Level    string         `csv:"LEVEL,enum=low|high,icase"`
+	Code     string         `csv:"CODE,upper,enum=CH|DE"`
+	Priority int            `csv:"PRIORITY,enum=1|2|3,default=1"`
+	Flags    map[string]int `csv:"FLAG_*,glob,enum=0|1"`
+}
+
+func TestUnmarshalEnum(t *testing.T) {
+	data := `STATUS;LEVEL;CODE;PRIORITY;FLAG_a
+active;LOW;ch;02;1
+Active;low;ch;1;1
+pending;High;de;4;0
+inactive;medium;de;2;0
+inactive;high;fr;2;0
+inactive;high;de;;2
+pending;low;ch;;0`
+	m, err := NewMarshaler(EnumStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	want := []interface{}{
+		EnumStruct{Status: "active", Level: "LOW", Code: "CH", Priority: 2, Flags: map[string]int{"a": 1}},
+		EnumStruct{Status: "pending", Level: "low", Code: "CH", Priority: 1, Flags: map[string]int{"a": 0}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 5 {
+		t.Fatalf("wrong errors - want: 5 ParseErrors, got: %v", err)
+	}
+	wantHeaders := []string{"STATUS", "PRIORITY", "LEVEL", "CODE", "FLAG_a"}
+	for i, e := range pe {
+		var fe *FieldError
+		if !errors.As(e.Err, &fe) || fe.Header != wantHeaders[i] || !errors.Is(e.Err, ErrNotInEnum) {
+			t.Errorf("wrong error %d - want: %s error for %s, got: %v", i, ErrNotInEnum, wantHeaders[i], e)
+		}
+	}
+	if msg := pe[0].Err.Error(); !strings.Contains(msg, "Status") || !strings.Contains(msg, "active|inactive|pending") {
+		t.Errorf("error does not name field and allowed values: %s", msg)
+	}
+}
+
+func TestEnumInvalidTags(t *testing.T) {
+	var invalidTagTests = map[string]interface{}{
+		"invalid int": struct {
+			F int `csv:"F,enum=1|two"`
+		}{},
+		"int overflow": struct {
+			F int8 `csv:"F,enum=1|300"`
+		}{},
+		"float": struct {
+			F float64 `csv:"F,enum=1|2"`
+		}{},
+		"icase int": struct {
+			F int `csv:"F,enum=1|2,icase"`
+		}{},
+		"icase only": struct {
+			F string `csv:"F,icase"`
+		}{},
+	}
+	for name, s := range invalidTagTests {
+		if _, err := NewSchema(s); err == nil {
+			t.Errorf("no error for test '%s', but it should", name)
+		}
+	}
+}
+
+type MatchStruct struct {
+	IBAN    string `csv:"IBAN,match=^CH\\d{19}$"`
+	Article int    `csv:"ARTICLE,match='^\\d{1,3}$'"`
+}
+
+func TestUnmarshalMatch(t *testing.T) {
+	data := `IBAN;ARTICLE
+CH9300762011623852957;12
+DE9300762011623852957;12
+CH9300762011623852957;1234
+CH9300762011623852957;abc`
+	m, err := NewMarshaler(MatchStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	want := []interface{}{MatchStruct{IBAN: "CH9300762011623852957", Article: 12}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 3 {
+		t.Fatalf("wrong errors - want: 3 ParseErrors, got: %v", err)
+	}
+	wantHeaders := []string{"IBAN", "ARTICLE", "ARTICLE"}
+	for i, e := range pe {
+		var fe *FieldError
+		if !errors.As(e.Err, &fe) || fe.Header != wantHeaders[i] || !errors.Is(e.Err, ErrNoMatch) {
+			t.Errorf("wrong error %d - want: %s error for %s, got: %v", i, ErrNoMatch, wantHeaders[i], e)
+		}
+	}
+
+	type invalidMatchStruct struct {
+		F string `csv:"F,match=[a-"`
+	}
+	if _, err := NewSchema(invalidMatchStruct{}); err == nil {
+		t.Error("no error for invalid pattern, but it should")
+	}
+}
+
+type BoundsStruct struct {
+	Quantity    int                `csv:"QTY,min=0,max=10000"`
+	Count       uint8              `csv:"COUNT,max=100,default=5"`
+	Temperature float64            `csv:"TEMP,min=-273.15,max=1000"`
+	Readings    map[string]float32 `csv:"R_*,glob,min=0"`
+}
+
+func TestUnmarshalBounds(t *testing.T) {
+	data := `QTY;COUNT;TEMP;R_a
+0;100;-273.15;0
+10000;;1000;1.5
+-1;1;20;1
+1;101;20;1
+1;1;1000.5;1
+1;1;20;-0.5`
+	m, err := NewMarshaler(BoundsStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.Comma = ';'
+	result, err := m.Unmarshal()
+	want := []interface{}{
+		BoundsStruct{Quantity: 0, Count: 100, Temperature: -273.15, Readings: map[string]float32{"a": 0}},
+		BoundsStruct{Quantity: 10000, Count: 5, Temperature: 1000, Readings: map[string]float32{"a": 1.5}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("wrong result - want: %v, got: %v", want, result)
+	}
+	pe, ok := err.(ParseErrors)
+	if !ok || len(pe) != 4 {
+		t.Fatalf("wrong errors - want: 4 ParseErrors, got: %v", err)
+	}
+	wantMessages := []string{"-1, min: 0", "101, max: 100", "1000.5, max: 1000", "-0.5, min: 0"}
+	for i, e := range pe {
+		if !errors.Is(e.Err, ErrOutOfBounds) || !strings.Contains(e.Err.Error(), wantMessages[i]) {
+			t.Errorf("wrong error %d - want: %s with %q, got: %v", i, ErrOutOfBounds, wantMessages[i], e)
+		}
+	}
+
+	// a default value is checked too
+	type defaultStruct struct {
+		F int `csv:"F,default=-1,min=0"`
+		G int `csv:"G"`
+	}
+	m, err = NewMarshaler(defaultStruct{}, strings.NewReader("F,G\n,1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Unmarshal(); err == nil || !errors.Is(err.(ParseErrors)[0].Err, ErrOutOfBounds) {
+		t.Errorf("wrong error for default value - want: %s, got: %v", ErrOutOfBounds, err)
+	}
+}
+
+func TestBoundsInvalidTags(t *testing.T) {
+	var invalidTagTests = map[string]interface{}{
+		"string": struct {
+			F string `csv:"F,min=1"`
+		}{},
+		"invalid int": struct {
+			F int `csv:"F,max=1.5"`
+		}{},
+		"negative": struct {
+			F uint `csv:"F,min=-1"`
+		}{},
+		"overflow": struct {
+			F int8 `csv:"F,max=128"`
+		}{},
+		"min over max": struct {
+			F float64 `csv:"F,min=2,max=1"`
+		}{},
+	}
+	for name, s := range invalidTagTests {
+		if _, err := NewSchema(s); err == nil {
+			t.Errorf("no error for test '%s', but it should", name)
+		}
+	}
+}
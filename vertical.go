@@ -0,0 +1,113 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// WithIgnoreUnknownKeys ignores the keys of UnmarshalVertical without field instead of failing
+// with a HeaderError.
+func WithIgnoreUnknownKeys() Option {
+	return func(m *Marshaler) {
+		m.ignoreUnknownKeys = true
+	}
+}
+
+// UnmarshalVertical decodes a vertical csv file of key and value records, e.g. FIELD_0;a and
+// FIELD_1;1, into the endpoint struct sPtr points to, configured with opts. The file has no
+// header, the keys are mapped to the csv tags like the header names of Unmarshal, with the
+// aliases and fuzzy matching of opts, and the values are converted like cells. Records without
+// exactly two cells fail with csv.ErrFieldCount. Unknown keys, duplicate keys and missing keys of
+// fields without default option are a HeaderError, unknown keys are ignored with
+// WithIgnoreUnknownKeys. A value that cannot be converted is returned as csv.ParseError with the
+// line of its record and column 1.
+func UnmarshalVertical(sPtr interface{}, r io.Reader, opts ...Option) error {
+	v := reflect.ValueOf(sPtr)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("UnmarshalVertical requires a pointer to an endpoint struct")
+	}
+	m, err := NewMarshaler(v.Elem().Interface(), r, opts...)
+	if err != nil {
+		return err
+	}
+	m.resolveDelimiter()
+	m.Reader.FieldsPerRecord = 2
+	keys, values, lines := []string{}, []string{}, []int{}
+	for {
+		record, err := m.Reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := m.Reader.FieldPos(0)
+		keys, values, lines = append(keys, record[0]), append(values, record[1]), append(lines, line)
+	}
+	header, err := m.aliasHeader(m.mapHeader(keys))
+	if err != nil {
+		return err
+	}
+	header, values, lines, err = m.verticalHeader(header, values, lines)
+	if err != nil {
+		return err
+	}
+	if err := m.resolveHeader(header); err != nil {
+		return err
+	}
+	if perr := m.decode(sPtr, values, 0); perr != nil {
+		if perr.Column >= 0 && perr.Column < len(lines) { // the value column of the record
+			perr.StartLine, perr.Line, perr.Column = lines[perr.Column], lines[perr.Column], 1
+		}
+		m.labelError(perr)
+		return perr
+	}
+	return nil
+}
+
+// verticalHeader checks the keys of a vertical file in header with validateHeader. It drops the
+// ignored unknown keys and adds an empty value for every missing field with default option.
+func (m *Marshaler) verticalHeader(header, values []string, lines []int) ([]string, []string, []int, error) {
+	herr := &HeaderError{}
+	if err := validateHeader(m.fieldInfos, header, m.RequireUntagged); !errors.As(err, &herr) {
+		return header, values, lines, err
+	}
+	unknown := map[string]bool{}
+	for _, name := range herr.Extra {
+		unknown[name] = true
+	}
+	missing := herr.Missing
+	herr.Missing = nil
+	for _, name := range missing {
+		if m.fieldInfos.hasDefault(name) {
+			header, values, lines = append(header, name), append(values, ""), append(lines, 0)
+			continue
+		}
+		herr.Missing = append(herr.Missing, name)
+	}
+	if m.ignoreUnknownKeys {
+		herr.Extra = nil
+	}
+	if len(herr.Missing) > 0 || len(herr.Extra) > 0 || len(herr.Duplicates) > 0 {
+		return nil, nil, nil, herr
+	}
+	known := header[:0:0]
+	knownValues, knownLines := values[:0:0], lines[:0:0]
+	for i, name := range header {
+		if !unknown[name] {
+			known, knownValues, knownLines = append(known, name), append(knownValues, values[i]), append(knownLines, lines[i])
+		}
+	}
+	return known, knownValues, knownLines, nil
+}
+
+// hasDefault checks if the field with the csv header name has the default option.
+func (fieldInfos fieldInfos) hasDefault(headerName string) bool {
+	for _, fieldInfo := range fieldInfos {
+		if _, ok := fieldInfo.options["default"]; ok && fieldInfo.headerName == headerName {
+			return true
+		}
+	}
+	return false
+}
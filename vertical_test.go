@@ -0,0 +1,112 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type VerticalStruct struct {
+	Field0 string  `csv:"FIELD_0"`
+	Field1 int     `csv:"FIELD_1"`
+	Field2 bool    `csv:"FIELD_2,default=true"`
+	Field3 float64 `csv:"FIELD_3"`
+}
+
+func TestUnmarshalVertical(t *testing.T) {
+	tt := map[string]struct {
+		data string
+		opts []Option
+		want VerticalStruct
+	}{
+		"all keys": {
+			data: "FIELD_0;string1\nFIELD_1;1\nFIELD_2;false\nFIELD_3;1.5\n",
+			want: VerticalStruct{Field0: "string1", Field1: 1, Field3: 1.5},
+		},
+		"default": {
+			data: "FIELD_3;1.5\nFIELD_1;1\nFIELD_0;a\n",
+			want: VerticalStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5},
+		},
+		"aliases and fuzzy match": {
+			data: "OLD_0;a\nfield 1;1\nFIELD_3;1.5\n",
+			opts: []Option{WithHeaderAliases(map[string]string{"OLD_0": "FIELD_0"}), WithFuzzyHeaderMatch()},
+			want: VerticalStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5},
+		},
+		"unknown keys ignored": {
+			data: "FIELD_0;a\nFIELD_1;1\nFIELD_3;1.5\nCOMMENT;x\n2024;y\n",
+			opts: []Option{WithIgnoreUnknownKeys()},
+			want: VerticalStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5},
+		},
+		"delimiter and comments": {
+			data: "# settings\nFIELD_0||a\nFIELD_1||1\nFIELD_3||1.5\n",
+			opts: []Option{WithDelimiter("||"), WithComment('#')},
+			want: VerticalStruct{Field0: "a", Field1: 1, Field2: true, Field3: 1.5},
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			s := VerticalStruct{}
+			opts := append([]Option{func(m *Marshaler) { m.Reader.Comma = ';' }}, tc.opts...)
+			if err := UnmarshalVertical(&s, strings.NewReader(tc.data), opts...); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s, tc.want) {
+				t.Errorf("wrong result - want: %v, got: %v", tc.want, s)
+			}
+		})
+	}
+}
+
+func TestUnmarshalVerticalErrors(t *testing.T) {
+	tt := map[string]struct {
+		data    string
+		want    *HeaderError
+		wantErr error
+	}{
+		"unknown key": {
+			data: "FIELD_0,a\nFIELD_1,1\nFIELD_3,1.5\nCOMMENT,x\n",
+			want: &HeaderError{Extra: []string{"COMMENT"}},
+		},
+		"duplicate key": {
+			data: "FIELD_0,a\nFIELD_1,1\nFIELD_3,1.5\nFIELD_1,2\n",
+			want: &HeaderError{Duplicates: []string{"FIELD_1"}},
+		},
+		"missing key": {
+			data: "FIELD_0,a\n",
+			want: &HeaderError{Missing: []string{"FIELD_1", "FIELD_3"}},
+		},
+		"three cells": {
+			data:    "FIELD_0,a\nFIELD_1,1,2\n",
+			wantErr: csv.ErrFieldCount,
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := UnmarshalVertical(&VerticalStruct{}, strings.NewReader(tc.data))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("wrong error - want: %v, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			var herr *HeaderError
+			if !errors.As(err, &herr) || !reflect.DeepEqual(herr, tc.want) {
+				t.Errorf("wrong error - want: %v, got: %v", tc.want, err)
+			}
+		})
+	}
+
+	// conversion errors have the line of the key
+	err := UnmarshalVertical(&VerticalStruct{}, strings.NewReader("FIELD_0,a\nFIELD_1,x\nFIELD_3,1.5\n"))
+	var pe *csv.ParseError
+	var fe *FieldError
+	if !errors.As(err, &pe) || pe.Line != 2 || pe.Column != 1 || !errors.As(err, &fe) || fe.Field != "Field1" {
+		t.Errorf("wrong error - want: error of Field1 on line 2, got: %v", err)
+	}
+
+	if err := UnmarshalVertical(VerticalStruct{}, strings.NewReader("")); err == nil {
+		t.Error("expected error for struct instead of pointer")
+	}
+}
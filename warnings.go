@@ -0,0 +1,82 @@
+package csv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+)
+
+// Diagnostics is implemented by ParseErrors and Warnings, e.g. to log both the same way.
+type Diagnostics interface {
+	json.Marshaler
+	Len() int                  // number of entries
+	Entries() []csv.ParseError // entries with line, column and, for cells, a FieldError
+	Summary() []ErrorGroup     // entries grouped by column and cause like ParseErrors.Summary
+	Severity() string          // "error" for ParseErrors, "warning" for Warnings
+}
+
+// Warnings is a slice of csv.ParseError of notices that do not fail the parse, e.g. rows with
+// extra columns decoded because of IgnoreExtraColumns or cells zeroed by ZeroOnError. They have
+// the same line, column and FieldError context as ParseErrors, with WarningsAsErrors they are
+// returned as errors instead.
+type Warnings []csv.ParseError
+
+// String returns the Warnings as string like ParseErrors.Error.
+func (ws Warnings) String() string {
+	return ParseErrors(ws).Error()
+}
+
+// Len is the number of warnings
+func (ws Warnings) Len() int {
+	return len(ws)
+}
+
+// Entries returns the warnings as slice of csv.ParseError
+func (ws Warnings) Entries() []csv.ParseError {
+	return ws
+}
+
+// Summary groups the warnings like ParseErrors.Summary.
+func (ws Warnings) Summary() []ErrorGroup {
+	return ParseErrors(ws).Summary()
+}
+
+// Severity returns "warning"
+func (ws Warnings) Severity() string {
+	return "warning"
+}
+
+// Sort sorts the warnings by line and column
+func (ws Warnings) Sort() {
+	ParseErrors(ws).Sort()
+}
+
+// MarshalJSON returns the Warnings as json array like ParseErrors.MarshalJSON.
+func (ws Warnings) MarshalJSON() ([]byte, error) {
+	return ParseErrors(ws).MarshalJSON()
+}
+
+// warn records pe as warning in the Report. With WarningsAsErrors it is returned instead, the
+// caller fails the row or the parse with it like with any other error.
+func (m *Marshaler) warn(pe csv.ParseError) *csv.ParseError {
+	m.labelError(&pe)
+	if m.WarningsAsErrors {
+		return &pe
+	}
+	m.report.Warnings = append(m.report.Warnings, pe)
+	return nil
+}
+
+// shortRow records a data row with fewer columns than the header, the missing cells are decoded
+// as empty cells, as ErrShortRow warning. Rows of ErrorOnMissingCells are not recorded.
+func (m *Marshaler) shortRow(record []string) *csv.ParseError {
+	if len(record) >= len(m.header) || m.ErrorOnMissingCells {
+		return nil
+	}
+	return m.warn(csv.ParseError{
+		StartLine: m.recordLine,
+		Line:      m.recordLine,
+		Column:    len(record),
+		Err:       fmt.Errorf("%w: %d instead of %d", ErrShortRow, len(record), len(m.header)),
+	})
+}
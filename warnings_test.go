@@ -0,0 +1,136 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	tt := map[string]struct {
+		data     string
+		opts     []Option
+		setup    func(m *Marshaler)
+		want     error // error of every warning
+		line     int
+		column   int
+		header   string
+		wantRows int
+	}{
+		"short row": {
+			data:     "FIELD_1,FIELD_2,FIELD_3,FIELD_0\n1,true,1.5,a\n2,false,2.5\n",
+			setup:    func(m *Marshaler) { m.Reader.FieldsPerRecord = -1 },
+			want:     ErrShortRow,
+			line:     3,
+			column:   3,
+			wantRows: 2,
+		},
+		"zeroed cell": {
+			data:     "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,x,true,1.5\n",
+			setup:    func(m *Marshaler) { m.OnConversionError = ZeroOnError },
+			want:     ErrZeroedCell,
+			line:     2,
+			column:   1,
+			header:   "FIELD_1",
+			wantRows: 1,
+		},
+		"fuzzy header match": {
+			data:     "FIELD_0,field 1,FIELD_2,FIELD_3\na,1,true,1.5\n",
+			opts:     []Option{WithFuzzyHeaderMatch()},
+			want:     ErrFuzzyHeaderMatch,
+			line:     1,
+			column:   1,
+			header:   "FIELD_1",
+			wantRows: 1,
+		},
+		"extra columns": {
+			data:     "FIELD_0,FIELD_1,FIELD_2,FIELD_3\na,1,true,1.5,x\n",
+			setup:    func(m *Marshaler) { m.IgnoreExtraColumns = true },
+			want:     ErrExtraColumns,
+			line:     2,
+			column:   4,
+			wantRows: 1,
+		},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMarshaler(TestStruct{}, strings.NewReader(tc.data), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.setup != nil {
+				tc.setup(m)
+			}
+			result, err := m.Unmarshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result) != tc.wantRows {
+				t.Errorf("wrong number of records - want: %d, got: %d", tc.wantRows, len(result))
+			}
+			warnings := m.Report().Warnings
+			if len(warnings) != 1 {
+				t.Fatalf("wrong warnings - want: 1 %s, got: %v", tc.want, warnings)
+			}
+			w := warnings[0]
+			if !errors.Is(w.Err, tc.want) || w.Line != tc.line || w.Column != tc.column {
+				t.Errorf("wrong warning - want: %s on line %d column %d, got: %v", tc.want, tc.line, tc.column, w)
+			}
+			var fe *FieldError
+			if tc.header != "" && (!errors.As(w.Err, &fe) || fe.Header != tc.header) {
+				t.Errorf("wrong header - want: %s, got: %v", tc.header, w.Err)
+			}
+
+			// promoted to errors
+			m, err = NewMarshaler(TestStruct{}, strings.NewReader(tc.data), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.setup != nil {
+				tc.setup(m)
+			}
+			m.WarningsAsErrors = true
+			m.Lazy = true
+			result, err = m.Unmarshal()
+			var pe *csv.ParseError
+			var errs ParseErrors
+			switch {
+			case errors.As(err, &errs) && len(errs) == 1:
+				pe = &errs[0]
+			case !errors.As(err, &pe):
+				t.Fatalf("wrong error - want: %s, got: %v", tc.want, err)
+			}
+			if !errors.Is(pe.Err, tc.want) || pe.Line != tc.line || pe.Column != tc.column {
+				t.Errorf("wrong error - want: %s on line %d column %d, got: %v", tc.want, tc.line, tc.column, pe)
+			}
+			if len(m.Report().Warnings) != 0 || len(result) >= tc.wantRows {
+				t.Errorf("wrong result - want: no warnings and fewer than %d records, got: %v and %d records", tc.wantRows, m.Report().Warnings, len(result))
+			}
+		})
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	data := "FIELD_1,FIELD_2,FIELD_3,FIELD_0\n1,true,1.5,a\nx,true,1.5,b\n2,false,2.5\n3,true,3.5\n"
+	m, err := NewMarshaler(TestStruct{}, strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Reader.FieldsPerRecord = -1
+	m.Lazy = true
+	_, err = m.Unmarshal()
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("wrong error - want: ParseErrors, got: %v", err)
+	}
+	want := map[string]int{"error": 1, "warning": 2}
+	for _, d := range []Diagnostics{errs, m.Report().Warnings} {
+		if d.Len() != want[d.Severity()] || len(d.Entries()) != d.Len() || len(d.Summary()) == 0 {
+			t.Errorf("wrong %s entries - want: %d, got: %v", d.Severity(), want[d.Severity()], d.Entries())
+		}
+		if b, err := d.MarshalJSON(); err != nil || !strings.Contains(string(b), `"line":`) {
+			t.Errorf("wrong json of %s entries: %s, %v", d.Severity(), b, err)
+		}
+	}
+}
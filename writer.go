@@ -0,0 +1,321 @@
+package csv
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oleiade/reflections"
+)
+
+var (
+	ErrWrongStructType = errors.New("struct does not match endpoint struct")
+	ErrWriterClosed    = errors.New("writer closed")
+)
+
+// formulaPrefixes are the leading characters that make spreadsheet applications
+// interpret a cell as formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// Writer marshals endpoint structs to a csv file. Cells are quoted like by encoding/csv, which
+// reads a \r\n line break inside a quoted cell back as \n, so such cells do not survive a round
+// trip unchanged.
+type Writer struct {
+	Writer            *csv.Writer
+	SanitizeFormulas  bool   // if true, cells that would be interpreted as formula are escaped with FormulaEscape, numbers are kept
+	FormulaEscape     string // prefix for sanitized cells, defaults to a single quote
+	CommentPrefix     string // prefix of the lines of WriteComments, defaults to DefaultCommentPrefix
+	BackslashEscapes  bool   // if true, tabs, line breaks and backslashes in cells of NewTSVWriter are written as \t, \n, \r and \\
+	SortColumnsByName bool   // if true, the columns are written in lexicographic order of their header names instead of the order of the struct fields; set it before the first Write
+	WriteBOM          bool   // if true, the UTF-8 byte order mark is written before the comments and the header, e.g. for Excel
+	OutputEncoding    string // encoding of the output, utf-8 by default or windows-1252; cells with other characters fail with ErrUnrepresentable
+	out               *outputWriter
+	started           bool          // if true, the output encoding is selected and the byte order mark written
+	tsv               *bufio.Writer // buffers the records of NewTSVWriter, nil for csv files
+	quoting           *bufio.Writer // buffers the records written with writeQuoted, nil without emptyquoted fields and records of one empty cell
+	fieldInfos        fieldInfos
+	endPointStruct    interface{}
+	records           int
+	headerWritten     bool
+	err               error // first error of Write, Flush or Close
+	formatters        map[reflect.Type]func(interface{}) (string, error)
+}
+
+// FieldMarshaler is implemented by field types that format themselves as csv cell.
+type FieldMarshaler interface {
+	MarshalCSV() (string, error)
+}
+
+// WriteError reports the record and field a Writer failed to format.
+type WriteError struct {
+	Record int    // zero based index of the record, -1 for the header
+	Field  string // name of the struct field
+	Err    error
+}
+
+// Error returns the WriteError as string
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("record:%d,field:%s,err:%s", e.Record, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// NewWriter returns a new Writer
+func NewWriter(endPointStruct interface{}, w io.Writer) (*Writer, error) {
+	schema, err := NewSchema(endPointStruct)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterWithSchema(schema, w), nil
+}
+
+// Header returns the csv header names of the endpoint struct s in tag order.
+func Header(s interface{}) ([]string, error) {
+	schema, err := NewSchema(s)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Header(), nil
+}
+
+// Record formats the fields of the endpoint struct s like a Writer and returns the cells in
+// tag order.
+func Record(s interface{}) ([]string, error) {
+	w, err := NewWriter(s, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	record, _, err := w.record(s)
+	return record, err
+}
+
+// RegisterFormatter registers a formatter for all fields of type t. The formatter has to be
+// a function of the form func(T) (string, error), where t is assignable to T.
+// Formatters take precedence over FieldMarshaler, encoding.TextMarshaler and the built-in formatting.
+func (w *Writer) RegisterFormatter(t reflect.Type, formatter interface{}) error {
+	fn := reflect.ValueOf(formatter)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return fmt.Errorf("formatter for %s must be of type func(%s) (string, error), got: %T", t, t, formatter)
+	}
+	if f, ok := formatter.(func(interface{}) (string, error)); ok {
+		w.formatters[t] = f
+		return nil
+	}
+	ft := fn.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || !t.AssignableTo(ft.In(0)) ||
+		ft.NumOut() != 2 || ft.Out(0).Kind() != reflect.String || ft.Out(1) != errorType {
+		return fmt.Errorf("formatter for %s must be of type func(%s) (string, error), got: %s", t, t, ft)
+	}
+	w.formatters[t] = func(v interface{}) (string, error) {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(v)})
+		err, _ := out[1].Interface().(error)
+		return out[0].String(), err
+	}
+	return nil
+}
+
+// Marshal writes the header and a record for every endpoint struct in structs and flushes the Writer.
+// A \r\n line break inside a cell is read back as \n, see Writer.
+func (w *Writer) Marshal(structs []interface{}) error {
+	for _, s := range structs {
+		if err := w.Write(s); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Write writes the endpoint struct s as record, the header is written before the first record.
+// Records are buffered until Flush or Close. After the first error all calls return it, so
+// callers can write in a loop and check the error of Close once.
+func (w *Writer) Write(s interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	if !w.headerWritten {
+		w.err = w.writeHeader()
+	}
+	if w.err == nil {
+		w.err = w.write(s)
+	}
+	return w.err
+}
+
+// Flush writes the buffered records to the underlying io.Writer, the header as well if no
+// record was written. After an error the records written before it are still flushed.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		w.flush()
+		return w.err
+	}
+	if !w.headerWritten {
+		w.err = w.writeHeader()
+	}
+	if err := w.flush(); w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// flush writes the buffers of the csv.Writer, NewTSVWriter and writeQuoted to the underlying
+// io.Writer, also after an error of Write.
+func (w *Writer) flush() error {
+	w.Writer.Flush()
+	if err := w.Writer.Error(); err != nil {
+		return err
+	}
+	if w.tsv != nil {
+		if err := w.tsv.Flush(); err != nil {
+			return err
+		}
+	}
+	if w.quoting != nil {
+		return w.quoting.Flush()
+	}
+	return nil
+}
+
+// Close flushes the Writer, also after an error, and returns the first error that occured,
+// subsequent writes fail with ErrWriterClosed. The underlying io.Writer is not closed.
+func (w *Writer) Close() error {
+	if w.err == ErrWriterClosed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.err = ErrWriterClosed
+	return nil
+}
+
+// writeHeader writes the header names of the endpoint struct, sorted with SortColumnsByName.
+func (w *Writer) writeHeader() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.SortColumnsByName {
+		sort.SliceStable(w.fieldInfos, func(i, j int) bool {
+			return w.fieldInfos[i].headerName < w.fieldInfos[j].headerName
+		})
+	}
+	header := make([]string, 0, len(w.fieldInfos))
+	for _, fieldInfo := range w.fieldInfos {
+		if err := w.out.check(fieldInfo.headerName); err != nil {
+			return &WriteError{Record: -1, Field: fieldInfo.fieldName, Err: err}
+		}
+		header = append(header, fieldInfo.headerName)
+	}
+	w.headerWritten = true
+	return w.writeRecord(header, nil)
+}
+
+// write writes a single endpoint struct as record.
+func (w *Writer) write(s interface{}) error {
+	record, quoted, err := w.record(s)
+	if err != nil {
+		return err
+	}
+	if err := w.writeRecord(record, quoted); err != nil {
+		return err
+	}
+	w.records++
+	return nil
+}
+
+// record formats the fields of s in header order, quoted marks the empty cells of emptyquoted
+// fields that are not null.
+func (w *Writer) record(s interface{}) (record []string, quoted []bool, err error) {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	if !v.IsValid() || v.Type() != reflect.TypeOf(w.endPointStruct) {
+		return nil, nil, ErrWrongStructType
+	}
+	s = v.Interface()
+	record = make([]string, 0, len(w.fieldInfos))
+	quoted = make([]bool, 0, len(w.fieldInfos))
+	for _, fieldInfo := range w.fieldInfos {
+		value, err := reflections.GetField(s, fieldInfo.fieldName)
+		if err != nil {
+			return nil, nil, err
+		}
+		cell, null, err := w.cell(fieldInfo, reflect.ValueOf(value))
+		if err == nil {
+			cell = w.sanitize(cell)
+			err = w.out.check(cell)
+		}
+		if err != nil {
+			return nil, nil, &WriteError{Record: w.records, Field: fieldInfo.fieldName, Err: err}
+		}
+		record = append(record, cell)
+		quoted = append(quoted, fieldInfo.isEmptyQuoted() && !null && len(cell) == 0)
+	}
+	return record, quoted, nil
+}
+
+// format formats a single field value. Types with a registered formatter, registered with RegisterType or implementing FieldMarshaler or
+// encoding.TextMarshaler format themselves.
+func (w *Writer) format(fieldInfo fieldInfo, v reflect.Value) (string, error) {
+	if formatter, ok := w.formatters[v.Type()]; ok {
+		return formatter(v.Interface())
+	}
+	if len(fieldInfo.layout) > 0 && v.Type() == timeType {
+		return v.Interface().(time.Time).Format(fieldInfo.layout), nil
+	}
+	if rt, ok := lookupType(v.Type()); ok {
+		return rt.format(v.Interface())
+	}
+	if formatter, ok := builtinFormatters[v.Type()]; ok {
+		return formatter(v.Interface())
+	}
+	// copy the value to make methods with pointer receivers available
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	switch m := ptr.Interface().(type) {
+	case FieldMarshaler:
+		return m.MarshalCSV()
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch fieldInfo.kind {
+	case reflect.Bool:
+		if _, ok := fieldInfo.options["boolnum"]; ok {
+			return formatBoolNum(v.Bool()), nil
+		}
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fieldInfo.scale > 0 {
+			return fieldInfo.formatScaled(v.Int()), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.String:
+		return v.String(), nil
+	}
+	return "", ErrUnsupportedCSVType
+}
+
+// sanitize escapes formatted cells that would be executed as formula by spreadsheet
+// applications. Numbers like -1.5 are kept, a sign is no formula on its own.
+func (w *Writer) sanitize(cell string) string {
+	if !w.SanitizeFormulas || len(cell) == 0 || !strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	return w.FormulaEscape + cell
+}
@@ -0,0 +1,565 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func TestMarshal(t *testing.T) {
+	structs := []interface{}{
+		firstLine,
+		TestStruct{Field0: "string2", Field1: 2, Field2: false, Field3: 2.14},
+	}
+	want := `FIELD_0;FIELD_1;FIELD_2;FIELD_3
+string1;1;true;1.14
+string2;2;false;2.14
+`
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(TestStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Writer.Comma = ';'
+	if err := w.Marshal(structs); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	if buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+}
+
+func TestMarshalWrongStructType(t *testing.T) {
+	type OtherStruct struct {
+		Field0 string `csv:"FIELD_0"`
+	}
+	w, err := NewWriter(TestStruct{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal([]interface{}{OtherStruct{}}); err != ErrWrongStructType {
+		t.Errorf("wrong error - want: %s, got: %v", ErrWrongStructType, err)
+	}
+}
+
+// failingWriter fails all writes after n bytes.
+type failingWriter struct {
+	n   int
+	err error
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > fw.n {
+		n := fw.n
+		fw.n = 0
+		return n, fw.err
+	}
+	fw.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriterStreaming(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(TestStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the header is written with the first record, so the Comma can still be changed
+	w.Writer.Comma = ';'
+	for _, s := range testStructs(2) {
+		if err := w.Write(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("records not buffered: %q", buf.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\nstring;0;true;1.5\nstring;1;true;1.5\n"; buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+	if err := w.Write(TestStruct{}); err != ErrWriterClosed {
+		t.Errorf("wrong error after Close - want: %s, got: %v", ErrWriterClosed, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("error in second Close: %s", err)
+	}
+
+	// without records Close writes the header
+	buf.Reset()
+	if w, err = NewWriter(TestStruct{}, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil || buf.String() != "FIELD_0,FIELD_1,FIELD_2,FIELD_3\n" {
+		t.Errorf("wrong output without records - want: header, got: %q %v", buf.String(), err)
+	}
+}
+
+func TestWriterStickyError(t *testing.T) {
+	sinkErr := errors.New("disk full")
+	w, err := NewWriter(TestStruct{}, &failingWriter{n: 100, err: sinkErr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// records are buffered, the sink fails on Flush
+	for _, s := range testStructs(10) {
+		if err := w.Write(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); !errors.Is(err, sinkErr) {
+		t.Errorf("wrong error of Flush - want: %s, got: %v", sinkErr, err)
+	}
+	if err := w.Write(TestStruct{}); !errors.Is(err, sinkErr) {
+		t.Errorf("wrong error of Write after failure - want: %s, got: %v", sinkErr, err)
+	}
+	if err := w.Close(); !errors.Is(err, sinkErr) {
+		t.Errorf("wrong error of Close - want: %s, got: %v", sinkErr, err)
+	}
+
+	// the first format error is sticky as well
+	w, err = NewWriter(TestStruct{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("no struct"); err != ErrWrongStructType {
+		t.Errorf("wrong error - want: %s, got: %v", ErrWrongStructType, err)
+	}
+	if err := w.Write(TestStruct{}); err != ErrWrongStructType {
+		t.Errorf("wrong error after failure - want: %s, got: %v", ErrWrongStructType, err)
+	}
+	if err := w.Close(); err != ErrWrongStructType {
+		t.Errorf("wrong error of Close - want: %s, got: %v", ErrWrongStructType, err)
+	}
+
+	// records buffered before the error are flushed by Close
+	buf := &bytes.Buffer{}
+	w, err = NewWriter(TestStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(TestStruct{Field0: "before"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("no struct"); err != ErrWrongStructType {
+		t.Errorf("wrong error - want: %s, got: %v", ErrWrongStructType, err)
+	}
+	if err := w.Close(); err != ErrWrongStructType {
+		t.Errorf("wrong error of Close - want: %s, got: %v", ErrWrongStructType, err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "before,") {
+		t.Errorf("record before the error not flushed: %q", buf.String())
+	}
+}
+
+func TestMarshalSanitizeFormulas(t *testing.T) {
+	var sanitizeTests = map[string]struct {
+		value    string
+		escape   string
+		sanitize bool
+		want     string
+	}{
+		"equal sign":         {"=1+1", "", true, "'=1+1"},
+		"plus sign":          {"+1+1", "", true, "'+1+1"},
+		"minus sign":         {"-1+1", "", true, "'-1+1"},
+		"at sign":            {"@SUM(A1:A2)", "", true, "'@SUM(A1:A2)"},
+		"cmd payload":        {"=cmd|' /C calc'!A0", "", true, "'=cmd|' /C calc'!A0"},
+		"tab payload":        {"\t=cmd|' /C calc'!A0", "", true, "'\t=cmd|' /C calc'!A0"},
+		"carriage return":    {"\r=cmd|' /C calc'!A0", "", true, "\"'\r=cmd|' /C calc'!A0\""},
+		"harmless":           {"string1", "", true, "string1"},
+		"empty":              {"", "", true, ""},
+		"custom escape":      {"=1+1", "_", true, "_=1+1"},
+		"sanitize disabled":  {"=1+1", "", false, "=1+1"},
+		"formula not at beg": {"a=1+1", "", true, "a=1+1"},
+		"negative number":    {"-5", "", true, "-5"},
+	}
+	for name, test := range sanitizeTests {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(TestStruct{}, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Writer.Comma = ';'
+		w.SanitizeFormulas = test.sanitize
+		if test.escape != "" {
+			w.FormulaEscape = test.escape
+		}
+		if err := w.Marshal([]interface{}{TestStruct{Field0: test.value, Field1: -1, Field3: -1.5}}); err != nil {
+			t.Fatalf("test '%s': error in Marshal: %s", name, err)
+		}
+		want := "FIELD_0;FIELD_1;FIELD_2;FIELD_3\n" + test.want + ";-1;false;-1.5\n"
+		if buf.String() != want {
+			t.Errorf("test '%s': wrong output - want: %q, got: %q", name, want, buf.String())
+		}
+	}
+}
+
+type money int64
+
+func (m money) MarshalCSV() (string, error) {
+	if m < 0 {
+		return "", errors.New("negative amount")
+	}
+	return fmt.Sprintf("CHF %d.%02d", m/100, m%100), nil
+}
+
+type formula string
+
+func (f formula) MarshalCSV() (string, error) {
+	return "=" + string(f), nil
+}
+
+type code string
+
+func (c *code) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(*c))), nil
+}
+
+func TestHeaderAndRecord(t *testing.T) {
+	header, err := Header(&TestStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"FIELD_0", "FIELD_1", "FIELD_2", "FIELD_3"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("wrong header - want: %v, got: %v", want, header)
+	}
+	record, err := Record(&TestStruct{Field0: "string", Field1: 1, Field2: true, Field3: 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"string", "1", "true", "1.5"}; !reflect.DeepEqual(record, want) {
+		t.Errorf("wrong record - want: %v, got: %v", want, record)
+	}
+
+	type CustomStruct struct {
+		Amount money `csv:"AMOUNT"`
+		Price  int64 `csv:"PRICE,scale=2"`
+	}
+	record, err = Record(CustomStruct{Amount: 1234, Price: 1234})
+	if want := []string{"CHF 12.34", "12.34"}; err != nil || !reflect.DeepEqual(record, want) {
+		t.Errorf("wrong record - want: %v, got: %v, %v", want, record, err)
+	}
+	_, err = Record(CustomStruct{Amount: -1})
+	if we, ok := err.(*WriteError); !ok || we.Field != "Amount" {
+		t.Errorf("wrong error - want: WriteError for Amount, got: %v", err)
+	}
+	if _, err := Record("no struct"); err != ErrNoStruct {
+		t.Errorf("wrong error - want: %s, got: %v", ErrNoStruct, err)
+	}
+}
+
+func TestMarshalCustomFormatting(t *testing.T) {
+	type CustomStruct struct {
+		Amount money `csv:"AMOUNT"`
+		Code   code  `csv:"CODE"`
+	}
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(CustomStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SanitizeFormulas = true
+	if err := w.Marshal([]interface{}{CustomStruct{Amount: 1234, Code: "ch"}}); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	want := "AMOUNT,CODE\nCHF 12.34,CH\n"
+	if buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+
+	// cells of FieldMarshaler and encoding.TextMarshaler fields are sanitized after formatting
+	type FormulaStruct struct {
+		Formula formula `csv:"FORMULA"`
+		Code    code    `csv:"CODE"`
+	}
+	buf = &bytes.Buffer{}
+	w, err = NewWriter(FormulaStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SanitizeFormulas = true
+	if err := w.Marshal([]interface{}{FormulaStruct{Formula: "SUM(A1:A2)", Code: "@ch"}}); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	want = "FORMULA,CODE\n'=SUM(A1:A2),'@CH\n"
+	if buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+
+	w, err = NewWriter(CustomStruct{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Marshal([]interface{}{CustomStruct{Amount: 1}, CustomStruct{Amount: -1}})
+	we, ok := err.(*WriteError)
+	if !ok {
+		t.Fatalf("wrong error - want: *WriteError, got: %v", err)
+	}
+	if we.Record != 1 || we.Field != "Amount" {
+		t.Errorf("wrong error context - want record 1 and field Amount, got: %s", we)
+	}
+}
+
+type roundTripStruct struct {
+	String  string  `csv:"STRING"`
+	Bool    bool    `csv:"BOOL"`
+	Int     int     `csv:"INT"`
+	Int8    int8    `csv:"INT8"`
+	Int16   int16   `csv:"INT16"`
+	Int32   int32   `csv:"INT32"`
+	Int64   int64   `csv:"INT64"`
+	Float32 float32 `csv:"FLOAT32"`
+	Float64 float64 `csv:"FLOAT64"`
+}
+
+// roundTripAlphabet contains the characters that need quoting or escaping.
+var roundTripAlphabet = []rune{'a', 'Z', '0', ' ', ';', ',', '"', '\'', '\n', '\r', '\t', '=', '-', 'ä', '€'}
+
+// Generate implements quick.Generator.
+func (roundTripStruct) Generate(r *rand.Rand, size int) reflect.Value {
+	runes := make([]rune, r.Intn(size+1))
+	for i := range runes {
+		runes[i] = roundTripAlphabet[r.Intn(len(roundTripAlphabet))]
+	}
+	return reflect.ValueOf(roundTripStruct{
+		String:  string(runes),
+		Bool:    r.Intn(2) == 1,
+		Int:     int(r.Uint64()),
+		Int8:    int8(r.Uint32()),
+		Int16:   int16(r.Uint32()),
+		Int32:   int32(r.Uint32()),
+		Int64:   int64(r.Uint64()),
+		Float32: float32(r.NormFloat64() * math.Pow(10, float64(r.Intn(60)-30))),
+		Float64: r.NormFloat64() * math.Pow(10, float64(r.Intn(600)-300)),
+	})
+}
+
+func roundTrip(structs []interface{}, comma rune) ([]interface{}, error) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(roundTripStruct{}, buf)
+	if err != nil {
+		return nil, err
+	}
+	w.Writer.Comma = comma
+	if err := w.Marshal(structs); err != nil {
+		return nil, err
+	}
+	m, err := NewMarshaler(roundTripStruct{}, buf)
+	if err != nil {
+		return nil, err
+	}
+	m.Reader.Comma = comma
+	return m.Unmarshal()
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, comma := range []rune{',', ';', '\t'} {
+		f := func(s roundTripStruct) bool {
+			result, err := roundTrip([]interface{}{s}, comma)
+			if err != nil {
+				t.Logf("error in round trip of %#v: %s", s, err)
+				return false
+			}
+			// encoding/csv reads \r\n inside quoted cells as \n
+			s.String = strings.ReplaceAll(s.String, "\r\n", "\n")
+			return len(result) == 1 && reflect.DeepEqual(result[0], s)
+		}
+		if err := quick.Check(f, nil); err != nil {
+			t.Errorf("round trip with comma %q failed: %s", comma, err)
+		}
+	}
+}
+
+func TestRoundTripEdgeCases(t *testing.T) {
+	structs := []interface{}{
+		roundTripStruct{String: `"quoted"`, Float64: math.Copysign(0, -1), Float32: float32(math.Copysign(0, -1))},
+		roundTripStruct{String: "a;b,c", Float64: 0.1 + 0.2, Float32: 1.1},
+		roundTripStruct{String: "line1\nline2", Float64: math.MaxFloat64, Float32: math.MaxFloat32},
+		roundTripStruct{String: " leading and trailing ", Float64: math.SmallestNonzeroFloat64, Float32: math.SmallestNonzeroFloat32},
+		roundTripStruct{String: "", Int: math.MinInt64, Int8: math.MinInt8, Int16: math.MinInt16, Int32: math.MinInt32, Int64: math.MinInt64},
+		roundTripStruct{String: "€", Int: math.MaxInt64, Int8: math.MaxInt8, Int16: math.MaxInt16, Int32: math.MaxInt32, Int64: math.MaxInt64, Float64: math.Inf(-1), Float32: float32(math.Inf(1))},
+		roundTripStruct{Float64: 123456789012345678},
+	}
+	result, err := roundTrip(structs, ';')
+	if err != nil {
+		t.Fatalf("error in round trip: %s", err)
+	}
+	if len(result) != len(structs) {
+		t.Fatalf("wrong number of records - want: %d, got: %d", len(structs), len(result))
+	}
+	for i, s := range structs {
+		if !reflect.DeepEqual(result[i], s) {
+			t.Errorf("wrong value after round trip - want: %#v, got: %#v", s, result[i])
+		}
+	}
+	negZero := result[0].(roundTripStruct)
+	if !math.Signbit(negZero.Float64) || !math.Signbit(float64(negZero.Float32)) {
+		t.Errorf("negative zero lost in round trip: %#v", negZero)
+	}
+
+	// \r\n inside a cell is read back as \n, a lone \r is kept
+	result, err = roundTrip([]interface{}{roundTripStruct{String: "a\r\nb"}, roundTripStruct{String: "a\rb"}}, ',')
+	if err != nil {
+		t.Fatalf("error in round trip: %s", err)
+	}
+	if got := []string{result[0].(roundTripStruct).String, result[1].(roundTripStruct).String}; !reflect.DeepEqual(got, []string{"a\nb", "a\rb"}) {
+		t.Errorf("wrong line breaks after round trip - want: [\"a\\nb\" \"a\\rb\"], got: %q", got)
+	}
+}
+
+func TestMarshalPointerStructs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(&TestStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := firstLine
+	if err := w.Marshal([]interface{}{firstLine, &str}); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	want := "FIELD_0,FIELD_1,FIELD_2,FIELD_3\nstring1,1,true,1.14\nstring1,1,true,1.14\n"
+	if buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+	var nilStruct *TestStruct
+	if err := w.Marshal([]interface{}{nilStruct}); err != ErrWrongStructType {
+		t.Errorf("wrong error - want: %s, got: %v", ErrWrongStructType, err)
+	}
+}
+
+func TestMarshalFormatter(t *testing.T) {
+	type FormatterStruct struct {
+		ID    [2]byte `csv:"ID"`
+		Delay money   `csv:"DELAY"`
+	}
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(FormatterStruct{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.RegisterFormatter(reflect.TypeOf([2]byte{}), func(b [2]byte) (string, error) {
+		return hex.EncodeToString(b[:]), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// formatters take precedence over MarshalCSV
+	err = w.RegisterFormatter(reflect.TypeOf(money(0)), func(v interface{}) (string, error) {
+		return fmt.Sprint(int64(v.(money))), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Marshal([]interface{}{FormatterStruct{ID: [2]byte{1, 0xff}, Delay: -1}}); err != nil {
+		t.Fatalf("error in Marshal: %s", err)
+	}
+	want := "ID,DELAY\n01ff,-1\n"
+	if buf.String() != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, buf.String())
+	}
+
+	invalidFormatters := []interface{}{
+		func(b []byte) (string, error) { return "", nil },
+		func(b [2]byte) string { return "" },
+		func(b [2]byte) (int, error) { return 0, nil },
+		"no function",
+		nil,
+		(func(interface{}) (string, error))(nil),
+	}
+	for _, formatter := range invalidFormatters {
+		if err := w.RegisterFormatter(reflect.TypeOf([2]byte{}), formatter); err == nil {
+			t.Errorf("no error for invalid formatter %T, but it should", formatter)
+		}
+	}
+}
+
+func TestWriterSortColumnsByName(t *testing.T) {
+	type declared struct {
+		Name  string `csv:"NAME"`
+		Age   int    `csv:"AGE"`
+		Email string `csv:"EMAIL"`
+	}
+	type regenerated struct {
+		Email string `csv:"EMAIL"`
+		Name  string `csv:"NAME"`
+		Age   int    `csv:"AGE"`
+	}
+	write := func(s interface{}) string {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(s, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.SortColumnsByName = true
+		if err := w.Marshal([]interface{}{s}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	want := "AGE,EMAIL,NAME\n42,a@b.c,a\n"
+	if got := write(declared{Name: "a", Age: 42, Email: "a@b.c"}); got != want {
+		t.Errorf("wrong output - want: %q, got: %q", want, got)
+	}
+	if got := write(regenerated{Email: "a@b.c", Name: "a", Age: 42}); got != want {
+		t.Errorf("wrong output of reordered struct - want: %q, got: %q", want, got)
+	}
+	if header, _ := Header(declared{}); !reflect.DeepEqual(header, []string{"NAME", "AGE", "EMAIL"}) {
+		t.Errorf("schema order changed - want: [NAME AGE EMAIL], got: %v", header)
+	}
+}
+
+func TestRoundTripSingleEmptyCell(t *testing.T) {
+	type single struct {
+		Name string `csv:"NAME"`
+	}
+	structs := []interface{}{single{Name: "a"}, single{}, single{Name: "b"}}
+	want := "NAME\na\n\"\"\nb\n"
+	chunk := &chunkSink{}
+	c, err := NewChunkedWriter(single{}, func(int) (io.WriteCloser, error) { return chunk, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(single{}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt := map[string]struct {
+		marshal func([]interface{}) error
+		output  *bytes.Buffer
+	}{
+		"writer":         {marshal: w.Marshal, output: buf},
+		"chunked writer": {marshal: c.Marshal, output: &chunk.Buffer},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.marshal(structs); err != nil {
+				t.Fatal(err)
+			}
+			if tc.output.String() != want {
+				t.Errorf("wrong output - want: %q, got: %q", want, tc.output.String())
+			}
+			m, err := NewMarshaler(single{}, tc.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.Unmarshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, structs) {
+				t.Errorf("wrong result - want: %v, got: %v", structs, result)
+			}
+		})
+	}
+}